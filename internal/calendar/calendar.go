@@ -0,0 +1,102 @@
+// Package calendar — производственный календарь: какие дни рабочие
+// и сколько в них часов. Обычная неделя — понедельник–пятница; праздники,
+// предпраздничные дни и переносы выходных задаются списком дней.
+package calendar
+
+import (
+	"maps"
+	"time"
+)
+
+// Kind — вид дня календаря
+type Kind string
+
+const (
+	// рабочий день, в том числе выходной, перенесенный на будни
+	Working Kind = "working"
+	Weekend Kind = "weekend"
+	// нерабочий праздничный день
+	Holiday Kind = "holiday"
+	// предпраздничный день, короче обычного на shortenedBy
+	Shortened Kind = "shortened"
+)
+
+// shortenedBy — на сколько сокращенный день короче обычного, если часы не заданы
+const shortenedBy = time.Hour
+
+// Day — день, который отличается от обычной недели
+type Day struct {
+	// 2006-01-02
+	Date string
+	Kind Kind
+	// часы работы; 0 — по виду дня: обычные или на час меньше в сокращенный
+	Hours time.Duration
+	Name  string
+}
+
+type Calendar struct {
+	// часы обычного рабочего дня
+	hours time.Duration
+	days  map[string]Day
+}
+
+// New создает календарь с рабочим днем hours; дни из days заменяют обычную неделю
+func New(hours time.Duration, days ...Day) *Calendar {
+	c := &Calendar{hours: hours, days: map[string]Day{}}
+	for _, d := range days {
+		c.days[d.Date] = d
+	}
+	return c
+}
+
+// With возвращает копию календаря, в которой дни days заменяют одноименные:
+// так календарь страны дополняется днями команды
+func (c *Calendar) With(days ...Day) *Calendar {
+	copied := &Calendar{hours: c.hours, days: maps.Clone(c.days)}
+	for _, d := range days {
+		copied.days[d.Date] = d
+	}
+	return copied
+}
+
+// Hours — часы обычного рабочего дня
+func (c *Calendar) Hours() time.Duration {
+	return c.hours
+}
+
+// Day возвращает особый день календаря, если date — не обычный день недели.
+// День берется в часовом поясе date.
+func (c *Calendar) Day(date time.Time) (Day, bool) {
+	d, ok := c.days[date.Format(time.DateOnly)]
+	return d, ok
+}
+
+func (c *Calendar) Kind(date time.Time) Kind {
+	if d, ok := c.Day(date); ok {
+		return d.Kind
+	}
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return Weekend
+	}
+	return Working
+}
+
+func (c *Calendar) IsWorkingDay(date time.Time) bool {
+	kind := c.Kind(date)
+	return kind == Working || kind == Shortened
+}
+
+// WorkingHours — норма часов дня date; в выходной и праздник 0
+func (c *Calendar) WorkingHours(date time.Time) time.Duration {
+	d, ok := c.Day(date)
+	if ok && d.Hours > 0 && (d.Kind == Working || d.Kind == Shortened) {
+		return d.Hours
+	}
+	switch c.Kind(date) {
+	case Working:
+		return c.hours
+	case Shortened:
+		return max(c.hours-shortenedBy, 0)
+	}
+	return 0
+}
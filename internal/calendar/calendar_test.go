@@ -0,0 +1,97 @@
+package calendar_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/tracker/internal/calendar"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestLoad(t *testing.T) {
+	days, err := calendar.Load("testdata/ru-2025.ics")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []calendar.Day{
+		{Date: "2025-05-01", Kind: calendar.Holiday, Name: "Праздник Весны и Труда, выходные"},
+		{Date: "2025-05-02", Kind: calendar.Holiday, Name: "Праздник Весны и Труда, выходные"},
+		{Date: "2025-04-30", Kind: calendar.Shortened, Name: "Предпраздничный день"},
+		{Date: "2025-11-01", Kind: calendar.Working, Name: "Рабочая суббота"},
+	}
+	if !reflect.DeepEqual(days, want) {
+		t.Errorf("Load(ics) = %+v, want %+v", days, want)
+	}
+
+	days, err = calendar.Load("testdata/backend.json")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(days) != 3 || days[2].Hours != 6*time.Hour {
+		t.Errorf("Load(json) = %+v", days)
+	}
+
+	for _, body := range []string{
+		`{"days": [{"date": "01.05.2025", "kind": "holiday"}]}`,
+		`{"days": [{"date": "2025-05-01", "kind": "vacation"}]}`,
+		`{"days": [{"date": "2025-05-01", "kind": "shortened", "hours": "7"}]}`,
+	} {
+		if _, err := calendar.ParseJSON(strings.NewReader(body)); err == nil {
+			t.Errorf("ParseJSON(%s) error = nil, want error", body)
+		}
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	ru, err := calendar.Load("testdata/ru-2025.ics")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	backend, err := calendar.Load("testdata/backend.json")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	base := calendar.New(8*time.Hour, ru...)
+	team := base.With(backend...)
+
+	tests := []struct {
+		name     string
+		calendar *calendar.Calendar
+		date     string
+		kind     calendar.Kind
+		hours    time.Duration
+	}{
+		{"Weekday", base, "2025-05-06", calendar.Working, 8 * time.Hour},
+		{"Weekend", base, "2025-05-03", calendar.Weekend, 0},
+		{"Holiday", base, "2025-05-01", calendar.Holiday, 0},
+		{"Shortened", base, "2025-04-30", calendar.Shortened, 7 * time.Hour},
+		{"Working Saturday", base, "2025-11-01", calendar.Working, 8 * time.Hour},
+		{"Team works on a holiday", team, "2025-05-02", calendar.Working, 8 * time.Hour},
+		{"Team day off", team, "2025-05-05", calendar.Holiday, 0},
+		{"Team shortened hours", team, "2025-05-07", calendar.Shortened, 6 * time.Hour},
+		{"Override leaves the base intact", base, "2025-05-05", calendar.Working, 8 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day := date(tt.date)
+			if got := tt.calendar.Kind(day); got != tt.kind {
+				t.Errorf("Kind(%s) = %v, want %v", tt.date, got, tt.kind)
+			}
+			if got := tt.calendar.WorkingHours(day); got != tt.hours {
+				t.Errorf("WorkingHours(%s) = %v, want %v", tt.date, got, tt.hours)
+			}
+			if got, want := tt.calendar.IsWorkingDay(day), tt.hours > 0; got != want {
+				t.Errorf("IsWorkingDay(%s) = %v, want %v", tt.date, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package calendar
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Load читает дни календаря из файла: .ics — iCalendar, остальное — JSON
+func Load(path string) ([]Day, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening calendar: %w", err)
+	}
+	defer f.Close()
+
+	var days []Day
+	if strings.EqualFold(filepath.Ext(path), ".ics") {
+		days, err = ParseICS(f)
+	} else {
+		days, err = ParseJSON(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing calendar %s: %w", path, err)
+	}
+	return days, nil
+}
+
+// jsonDay — день в JSON-файле:
+// {"days": [{"date": "2025-05-01", "kind": "holiday", "name": "Праздник Весны и Труда"}, {"date": "2025-04-30", "kind": "shortened", "hours": "7h"}]}
+type jsonDay struct {
+	Date  string `json:"date"`
+	Kind  Kind   `json:"kind"`
+	Hours string `json:"hours,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+func ParseJSON(r io.Reader) ([]Day, error) {
+	var file struct {
+		Days []jsonDay `json:"days"`
+	}
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("error decoding json: %w", err)
+	}
+	days := make([]Day, 0, len(file.Days))
+	for _, d := range file.Days {
+		day := Day{Date: d.Date, Kind: d.Kind, Name: d.Name}
+		if d.Hours != "" {
+			hours, err := time.ParseDuration(d.Hours)
+			if err != nil {
+				return nil, fmt.Errorf("day %s: error parsing hours: %w", d.Date, err)
+			}
+			day.Hours = hours
+		}
+		if err := day.validate(); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// ParseICS читает события VEVENT на целые дни. Вид дня задается в CATEGORIES
+// (holiday, shortened, working, weekend); без него событие считается праздником,
+// как в календарях государственных праздников. Событие на несколько дней
+// (DTEND не включается) дает каждый свой день; повторения RRULE не разворачиваются.
+func ParseICS(r io.Reader) ([]Day, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, err
+	}
+	var days []Day
+	var event map[string]string
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			event = map[string]string{}
+		case line == "END:VEVENT":
+			if event == nil {
+				return nil, errors.New("END:VEVENT without BEGIN")
+			}
+			eventDays, err := icsEventDays(event)
+			if err != nil {
+				return nil, err
+			}
+			days = append(days, eventDays...)
+			event = nil
+		case event != nil:
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			// параметры свойства (DTSTART;VALUE=DATE) не нужны: даты читаются по первым восьми символам
+			name, _, _ = strings.Cut(name, ";")
+			event[strings.ToUpper(name)] = value
+		}
+	}
+	return days, nil
+}
+
+// unfoldICS склеивает перенесенные строки: продолжение начинается с пробела или табуляции
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading ics: %w", err)
+	}
+	return lines, nil
+}
+
+func icsEventDays(event map[string]string) ([]Day, error) {
+	start, err := parseICSDate(event["DTSTART"])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing DTSTART: %w", err)
+	}
+	end := start.AddDate(0, 0, 1)
+	if value := event["DTEND"]; value != "" {
+		if end, err = parseICSDate(value); err != nil {
+			return nil, fmt.Errorf("error parsing DTEND: %w", err)
+		}
+	}
+	kind := Holiday
+	for _, category := range strings.Split(event["CATEGORIES"], ",") {
+		if k := Kind(strings.ToLower(strings.TrimSpace(category))); k.valid() {
+			kind = k
+			break
+		}
+	}
+	name := strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\\`, `\`).Replace(event["SUMMARY"])
+
+	var days []Day
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, Day{Date: d.Format(time.DateOnly), Kind: kind, Name: name})
+	}
+	return days, nil
+}
+
+// parseICSDate читает дату из 20250501 или 20250501T000000Z
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+func (k Kind) valid() bool {
+	switch k {
+	case Working, Weekend, Holiday, Shortened:
+		return true
+	}
+	return false
+}
+
+func (d Day) validate() error {
+	if _, err := time.Parse(time.DateOnly, d.Date); err != nil {
+		return fmt.Errorf("invalid date %q, expected 2006-01-02", d.Date)
+	}
+	if !d.Kind.valid() {
+		return fmt.Errorf("day %s: unknown kind %q", d.Date, d.Kind)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -34,24 +35,48 @@ func (c *Client) NewRequest(ctx context.Context, httpMethod, url string, body io
 	return req, nil
 }
 
+// NewJSONRequest создает запрос с телом, сериализованным в JSON
+func (c *Client) NewJSONRequest(ctx context.Context, httpMethod, url string, body interface{}) (*http.Request, error) {
+	if body == nil {
+		return c.NewRequest(ctx, httpMethod, url, nil)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request body: %w", err)
+	}
+	return c.NewRequest(ctx, httpMethod, url, bytes.NewReader(data))
+}
+
 func (c *Client) Do(request *http.Request, bodyInterface interface{}) (status int, err error) {
+	status, _, err = c.DoWithHeader(request, bodyInterface)
+	return status, err
+}
+
+// DoWithHeader выполняет запрос как Do и дополнительно возвращает заголовки ответа
+func (c *Client) DoWithHeader(request *http.Request, bodyInterface interface{}) (status int, header http.Header, err error) {
 	resp, err := c.httpClient.Do(request)
 	if err != nil {
-		return 0, fmt.Errorf("error making request to %s: %w", request.URL.Path, err)
+		return 0, nil, fmt.Errorf("error making request to %s: %w", request.URL.Path, err)
 	}
 	defer resp.Body.Close()
 
 	status = resp.StatusCode
-	if resp.StatusCode != http.StatusOK {
-		return status, fmt.Errorf("error: received status code %d", status)
+	header = resp.Header
+	if !IsSuccess(status) {
+		return status, header, newAPIError(resp)
 	}
 
-	if bodyInterface != nil {
+	if bodyInterface != nil && status != http.StatusNoContent {
 		decoder := json.NewDecoder(resp.Body)
-		if err = decoder.Decode(bodyInterface); err != nil {
-			return status, fmt.Errorf("error decoding response body: %w", err)
+		if err = decoder.Decode(bodyInterface); err != nil && err != io.EOF {
+			return status, header, fmt.Errorf("error decoding response body: %w", err)
 		}
 	}
 
-	return status, nil
+	return status, header, nil
+}
+
+// IsSuccess сообщает, является ли код ответа успешным (2xx)
+func IsSuccess(status int) bool {
+	return status >= 200 && status < 300
 }
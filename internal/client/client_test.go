@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -147,6 +149,11 @@ func TestExecRequest(t *testing.T) {
 		case "/success":
 			w.WriteHeader(http.StatusOK)
 			json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"status": "created"})
+		case "/nocontent":
+			w.WriteHeader(http.StatusNoContent)
 		case "/error":
 			w.WriteHeader(http.StatusBadRequest)
 		case "/timeout":
@@ -171,6 +178,20 @@ func TestExecRequest(t *testing.T) {
 			wantStatus:    http.StatusOK,
 			wantErr:       false,
 		},
+		{
+			name:          "Created request",
+			path:          "/created",
+			bodyInterface: &map[string]string{},
+			wantStatus:    http.StatusCreated,
+			wantErr:       false,
+		},
+		{
+			name:          "No content request",
+			path:          "/nocontent",
+			bodyInterface: &map[string]string{},
+			wantStatus:    http.StatusNoContent,
+			wantErr:       false,
+		},
 		{
 			name:          "Error request",
 			path:          "/error",
@@ -208,3 +229,96 @@ func TestExecRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestDoWithHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Total-Pages", "3")
+		w.Header().Set("Link", `<http://example.com/?page=2>; rel="next"`)
+		json.NewEncoder(w).Encode([]int{1, 2})
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body []int
+	status, header, err := client.New(nil).DoWithHeader(req, &body)
+	if err != nil {
+		t.Fatalf("DoWithHeader() error = %v", err)
+	}
+	if status != http.StatusOK {
+		t.Errorf("DoWithHeader() status = %v, want %v", status, http.StatusOK)
+	}
+	if got := header.Get("X-Total-Pages"); got != "3" {
+		t.Errorf("DoWithHeader() X-Total-Pages = %q, want %q", got, "3")
+	}
+	if len(body) != 2 {
+		t.Errorf("DoWithHeader() body = %v, want 2 items", body)
+	}
+}
+
+func TestNewJSONRequest(t *testing.T) {
+	httpClient := client.New(nil)
+
+	req, err := httpClient.NewJSONRequest(context.Background(), http.MethodPost, "http://example.com", map[string]string{"key": "value"})
+	if err != nil {
+		t.Fatalf("NewJSONRequest() error = %v", err)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"key":"value"}` {
+		t.Errorf("NewJSONRequest() body = %s, want %s", body, `{"key":"value"}`)
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("NewJSONRequest() Content-Type header not set correctly")
+	}
+
+	req, err = httpClient.NewJSONRequest(context.Background(), http.MethodDelete, "http://example.com", nil)
+	if err != nil {
+		t.Fatalf("NewJSONRequest() error = %v", err)
+	}
+	if req.Body != nil {
+		t.Errorf("NewJSONRequest() body = %v, want nil", req.Body)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"errors":{"issue":"not found"},"errorMessages":["Задача не существует."],"statusCode":404}`)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v3/issues/A-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.New(nil).Do(req, nil)
+
+	var apiErr *client.APIError
+	if !errors.As(fmt.Errorf("wrapped: %w", err), &apiErr) {
+		t.Fatalf("Do() error = %v, want *client.APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusCode = %v, want %v", apiErr.StatusCode, http.StatusNotFound)
+	}
+	if apiErr.Path != "/v3/issues/A-1" {
+		t.Errorf("Path = %v, want %v", apiErr.Path, "/v3/issues/A-1")
+	}
+	if len(apiErr.ErrorMessages) != 1 || apiErr.ErrorMessages[0] != "Задача не существует." {
+		t.Errorf("ErrorMessages = %v", apiErr.ErrorMessages)
+	}
+	if apiErr.Errors["issue"] != "not found" {
+		t.Errorf("Errors = %v", apiErr.Errors)
+	}
+	if !strings.Contains(string(apiErr.Body), `"statusCode":404`) {
+		t.Errorf("Body = %s", apiErr.Body)
+	}
+	if want := "error: received status code 404 from GET /v3/issues/A-1: Задача не существует.; issue: not found"; apiErr.Error() != want {
+		t.Errorf("Error() = %q, want %q", apiErr.Error(), want)
+	}
+}
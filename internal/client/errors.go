@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ограничение на размер тела ответа с ошибкой, которое сохраняется в APIError
+const maxErrorBodySize = 64 << 10
+
+// APIError — неуспешный ответ API вместе с разобранным телом ошибки Трекера
+type APIError struct {
+	StatusCode    int
+	Method        string
+	Path          string
+	ErrorMessages []string
+	Errors        map[string]string
+	Body          []byte
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("error: received status code %d from %s %s", e.StatusCode, e.Method, e.Path)
+	if details := e.Messages(); len(details) > 0 {
+		msg += ": " + strings.Join(details, "; ")
+	}
+	return msg
+}
+
+// Messages возвращает все сообщения об ошибках: общие и по полям
+func (e *APIError) Messages() []string {
+	messages := append([]string{}, e.ErrorMessages...)
+	for _, field := range slices.Sorted(maps.Keys(e.Errors)) {
+		messages = append(messages, field+": "+e.Errors[field])
+	}
+	return messages
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     resp.Request.Method,
+		Path:       resp.Request.URL.Path,
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return apiErr
+	}
+	apiErr.Body = body
+
+	var payload struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		apiErr.ErrorMessages = payload.ErrorMessages
+		if len(payload.Errors) > 0 {
+			apiErr.Errors = payload.Errors
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type RetryConfig struct {
+	// общее число попыток, включая первую; по умолчанию 3
+	MaxAttempts int
+	// коды ответа, при которых запрос повторяется; по умолчанию 429 и 5xx, кроме 501
+	StatusCodes []int
+	// задержка перед первым повтором, дальше удваивается; по умолчанию 500ms
+	BaseDelay time.Duration
+	// максимальная задержка между попытками, в том числе из Retry-After; по умолчанию 5s.
+	// Все попытки идут в пределах срока запроса (таймаут client.New и
+	// tracker.Config.Timeout — 10s), поэтому задержка должна быть заметно меньше его:
+	// повтор, ожидание которого не укладывается в срок запроса, не делается.
+	MaxDelay time.Duration
+	// повторять и неидемпотентные запросы (POST, PATCH)
+	RetryNonIdempotent bool
+}
+
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// Интерсептор для повтора запросов при 429 и 5xx с экспоненциальной задержкой
+func RetryInterceptor(config RetryConfig) Interceptor {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.StatusCodes == nil {
+		config.StatusCodes = defaultRetryStatusCodes
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = 500 * time.Millisecond
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 5 * time.Second
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &retryRoundTripper{next: next, config: config}
+	}
+}
+
+type retryRoundTripper struct {
+	next   http.RoundTripper
+	config RetryConfig
+}
+
+func (t *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.retryable(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if err != nil || attempt >= t.config.MaxAttempts || !slices.Contains(t.config.StatusCodes, resp.StatusCode) {
+			return resp, err
+		}
+
+		delay := t.delay(attempt, resp.Header.Get("Retry-After"))
+		// после такого ожидания запрос закончился бы таймаутом, а не ответом
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < delay {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *retryRoundTripper) retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if t.config.RetryNonIdempotent {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// delay возвращает задержку перед следующей попыткой: из Retry-After,
+// если сервер его прислал, иначе экспоненциальную со случайным разбросом
+func (t *retryRoundTripper) delay(attempt int, retryAfter string) time.Duration {
+	if d, ok := parseRetryAfter(retryAfter); ok {
+		return min(d, t.config.MaxDelay)
+	}
+	d := min(t.config.BaseDelay<<(attempt-1), t.config.MaxDelay)
+	return d/2 + rand.N(d/2+1)
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,157 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	client "example.com/tracker/internal/client"
+)
+
+func TestRetryInterceptor(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		config       client.RetryConfig
+		failures     int
+		failStatus   int
+		retryAfter   string
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{
+			name:         "Retries 503 until success",
+			method:       http.MethodGet,
+			failures:     2,
+			failStatus:   http.StatusServiceUnavailable,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 3,
+		},
+		{
+			name:         "Honours Retry-After on 429",
+			method:       http.MethodGet,
+			failures:     1,
+			failStatus:   http.StatusTooManyRequests,
+			retryAfter:   "0",
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "Stops after max attempts",
+			method:       http.MethodGet,
+			config:       client.RetryConfig{MaxAttempts: 2},
+			failures:     5,
+			failStatus:   http.StatusBadGateway,
+			wantStatus:   http.StatusBadGateway,
+			wantAttempts: 2,
+		},
+		{
+			name:         "Does not retry other statuses",
+			method:       http.MethodGet,
+			failures:     1,
+			failStatus:   http.StatusNotFound,
+			wantStatus:   http.StatusNotFound,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Does not retry POST by default",
+			method:       http.MethodPost,
+			failures:     1,
+			failStatus:   http.StatusServiceUnavailable,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Retries POST when allowed",
+			method:       http.MethodPost,
+			config:       client.RetryConfig{RetryNonIdempotent: true},
+			failures:     1,
+			failStatus:   http.StatusServiceUnavailable,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "Uses configured status codes",
+			method:       http.MethodGet,
+			config:       client.RetryConfig{StatusCodes: []int{http.StatusConflict}},
+			failures:     1,
+			failStatus:   http.StatusConflict,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					body := make([]byte, 64)
+					n, _ := r.Body.Read(body)
+					if string(body[:n]) != `{"key":"value"}` {
+						t.Errorf("attempt %d body = %q", attempts.Load()+1, body[:n])
+					}
+				}
+				if attempts.Add(1) <= int32(tt.failures) {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.failStatus)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			config := tt.config
+			config.BaseDelay = time.Millisecond
+			httpClient := client.New([]client.Interceptor{client.RetryInterceptor(config)})
+
+			req, err := httpClient.NewRequest(context.Background(), tt.method, server.URL, strings.NewReader(`{"key":"value"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			status, _ := httpClient.Do(req, nil)
+			if status != tt.wantStatus {
+				t.Errorf("status = %v, want %v", status, tt.wantStatus)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %v, want %v", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryInterceptorCancelledContext(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	httpClient := client.New([]client.Interceptor{client.RetryInterceptor(client.RetryConfig{MaxAttempts: 5})})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := httpClient.NewRequest(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	// ожидание Retry-After не укладывается в срок запроса: сразу возвращается последний ответ
+	if status, err := httpClient.Do(req, nil); status != http.StatusTooManyRequests || err == nil {
+		t.Errorf("Do() = %v, %v, want 429 error", status, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Do() took %v, want it not to wait past the deadline", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %v, want 1", got)
+	}
+}
@@ -2,22 +2,96 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"maps"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
+	"time"
+	// база часовых поясов на случай, если в системе ее нет (например, в контейнере)
+	_ "time/tzdata"
+)
+
+const (
+	// IAM-токен Yandex Cloud: YANDEX_IAM_TOKEN или YANDEX_SA_KEY_FILE
+	AuthSchemeIAM = "iam"
+	// OAuth-токен Яндекс ID: YANDEX_OAUTH_TOKEN
+	AuthSchemeOAuth = "oauth"
+
+	// организация Yandex Cloud Organization, заголовок X-Cloud-Org-ID
+	OrgTypeCloud = "cloud"
+	// организация Яндекс 360 для бизнеса, заголовок X-Org-ID
+	OrgType360 = "360"
 )
 
 type Config struct {
-	YandexIAMToken string
-	YandexOrgID    string
-	TrackerHost    string
-	ServerAddr     string
+	// YANDEX_AUTH_SCHEME: iam (по умолчанию) или oauth
+	AuthScheme string
+	// YANDEX_ORG_TYPE: cloud (по умолчанию) или 360
+	OrgType          string
+	YandexOAuthToken string
+	YandexIAMToken   string
+	// путь к авторизованному ключу сервисного аккаунта; если задан,
+	// IAM-токен получается и обновляется автоматически вместо YANDEX_IAM_TOKEN
+	YandexSAKeyFile string
+	YandexOrgID     string
+	TrackerHost     string
+	// TRACKER_API_URL: адрес API без версии, например локальный фейк или прокси
+	TrackerAPIURL string
+	// TRACKER_API_VERSION: v2 или v3
+	TrackerAPIVersion string
+	ServerAddr        string
+	// команды: название → логины участников, TEAMS=backend=alice,bob;frontend=carol
+	Teams map[string][]string
+	// CACHE_FILE: файл локального кэша записей; пустой — без кэша
+	CacheFile string
+	// CACHE_MAX_AGE: сколько кэш считается свежим, например 5m
+	CacheMaxAge time.Duration
+	// TIMEZONE: часовой пояс отчетов по умолчанию, Europe/Moscow, если не задан
+	Timezone *time.Location
+	// USER_TIMEZONES: часовые пояса участников, USER_TIMEZONES=alice=Europe/Berlin;bob=Asia/Yekaterinburg
+	UserTimezones map[string]*time.Location
+	// NORM_DAILY: часы обычного рабочего дня, по умолчанию 8h; 0 — нормы не проверяются
+	NormDaily time.Duration
+	// NORM_WEEKLY: норма недели; не задана — сумма дневных норм
+	NormWeekly time.Duration
+	// NORM_TOLERANCE: отклонение от нормы, в пределах которого день в норме
+	NormTolerance time.Duration
+	// CALENDAR_FILE: производственный календарь, .ics или .json; пустой — пн–пт без праздников
+	CalendarFile string
+	// CALENDAR_OVERRIDES: дни стран и команд поверх общего календаря, название → файл,
+	// CALENDAR_OVERRIDES=by=/etc/tracker/by.ics;backend=/etc/tracker/backend.json
+	CalendarOverrides map[string]string
+	// TEAM_CALENDARS: переопределения календаря команд по порядку, TEAM_CALENDARS=minsk=by,backend
+	TeamCalendars map[string][]string
+	// REMINDER_AT: время ежедневной проверки записей, 18:00; пустое — без напоминаний
+	ReminderAt string
+	// REMINDER_USERS: кому напоминать, логины через запятую; по умолчанию участники TEAMS
+	ReminderUsers []string
+	// PUBLIC_URL: адрес сервера для ссылок в напоминаниях
+	PublicURL string
+	// напоминания почтой: SMTP_ADDR (host:port), SMTP_FROM, SMTP_USERNAME, SMTP_PASSWORD;
+	// адрес участника — из USER_EMAILS=alice=alice@example.com, иначе login@EMAIL_DOMAIN
+	SMTPAddr     string
+	SMTPFrom     string
+	SMTPUsername string
+	SMTPPassword string
+	EmailDomain  string
+	UserEmails   map[string]string
+	// WEBHOOK_URL: напоминания POST-запросом с JSON
+	WebhookURL string
+	// напоминания через Telegram Bot API: TELEGRAM_BOT_TOKEN, TELEGRAM_API_URL
+	// и чаты участников TELEGRAM_CHATS=alice=123456;bob=654321
+	TelegramBotToken string
+	TelegramAPIURL   string
+	TelegramChats    map[string]string
 }
 
 func Load() (*Config, error) {
-	config := &Config{
-		YandexIAMToken: os.Getenv("YANDEX_IAM_TOKEN"),
-		YandexOrgID:    os.Getenv("YANDEX_ORG_ID"),
-		TrackerHost:    os.Getenv("TRACKER_HOST"),
-		ServerAddr:     getEnvOrDefault("SERVER_ADDR", ":8080"),
+	config, err := load()
+	if err != nil {
+		return nil, err
 	}
 
 	if err := config.validate(); err != nil {
@@ -27,9 +101,152 @@ func Load() (*Config, error) {
 	return config, nil
 }
 
+// LoadFake читает настройки для работы с фейковым Трекером: адрес API
+// и авторизация берутся из аргументов, остальное — из окружения
+func LoadFake(apiURL, token, orgID string) (*Config, error) {
+	config, err := load()
+	if err != nil {
+		return nil, err
+	}
+	config.AuthScheme = AuthSchemeIAM
+	config.OrgType = OrgTypeCloud
+	config.YandexIAMToken = token
+	config.YandexSAKeyFile = ""
+	config.YandexOrgID = orgID
+	// демо-данные не смешиваются с кэшем настоящего Трекера
+	config.CacheFile = ""
+	config.TrackerAPIURL = apiURL
+	config.TrackerAPIVersion = "v3"
+	if config.TrackerHost == "" {
+		config.TrackerHost = apiURL
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func load() (*Config, error) {
+	config := &Config{
+		AuthScheme:        getEnvOrDefault("YANDEX_AUTH_SCHEME", AuthSchemeIAM),
+		OrgType:           getEnvOrDefault("YANDEX_ORG_TYPE", OrgTypeCloud),
+		YandexOAuthToken:  os.Getenv("YANDEX_OAUTH_TOKEN"),
+		YandexIAMToken:    os.Getenv("YANDEX_IAM_TOKEN"),
+		YandexSAKeyFile:   os.Getenv("YANDEX_SA_KEY_FILE"),
+		YandexOrgID:       os.Getenv("YANDEX_ORG_ID"),
+		TrackerHost:       os.Getenv("TRACKER_HOST"),
+		TrackerAPIURL:     getEnvOrDefault("TRACKER_API_URL", "https://api.tracker.yandex.net"),
+		TrackerAPIVersion: getEnvOrDefault("TRACKER_API_VERSION", "v3"),
+		ServerAddr:        getEnvOrDefault("SERVER_ADDR", ":8080"),
+		CacheFile:         os.Getenv("CACHE_FILE"),
+		CalendarFile:      os.Getenv("CALENDAR_FILE"),
+		ReminderAt:        os.Getenv("REMINDER_AT"),
+		PublicURL:         os.Getenv("PUBLIC_URL"),
+		SMTPAddr:          os.Getenv("SMTP_ADDR"),
+		SMTPFrom:          os.Getenv("SMTP_FROM"),
+		SMTPUsername:      os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:      os.Getenv("SMTP_PASSWORD"),
+		EmailDomain:       os.Getenv("EMAIL_DOMAIN"),
+		WebhookURL:        os.Getenv("WEBHOOK_URL"),
+		TelegramBotToken:  os.Getenv("TELEGRAM_BOT_TOKEN"),
+		TelegramAPIURL:    os.Getenv("TELEGRAM_API_URL"),
+	}
+
+	cacheMaxAge, err := time.ParseDuration(getEnvOrDefault("CACHE_MAX_AGE", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("CACHE_MAX_AGE: %w", err)
+	}
+	config.CacheMaxAge = cacheMaxAge
+
+	timezone, err := time.LoadLocation(getEnvOrDefault("TIMEZONE", "Europe/Moscow"))
+	if err != nil {
+		return nil, fmt.Errorf("TIMEZONE: %w", err)
+	}
+	config.Timezone = timezone
+
+	userTimezones, err := parseUserTimezones(os.Getenv("USER_TIMEZONES"))
+	if err != nil {
+		return nil, err
+	}
+	config.UserTimezones = userTimezones
+
+	for _, norm := range []struct {
+		key, defaultValue string
+		value             *time.Duration
+	}{
+		{"NORM_DAILY", "8h", &config.NormDaily},
+		{"NORM_WEEKLY", "0", &config.NormWeekly},
+		{"NORM_TOLERANCE", "0", &config.NormTolerance},
+	} {
+		if *norm.value, err = time.ParseDuration(getEnvOrDefault(norm.key, norm.defaultValue)); err != nil {
+			return nil, fmt.Errorf("%s: %w", norm.key, err)
+		}
+	}
+	calendarOverrides, err := parseCalendarOverrides(os.Getenv("CALENDAR_OVERRIDES"))
+	if err != nil {
+		return nil, err
+	}
+	config.CalendarOverrides = calendarOverrides
+
+	teamCalendars, err := parseTeamCalendars(os.Getenv("TEAM_CALENDARS"))
+	if err != nil {
+		return nil, err
+	}
+	config.TeamCalendars = teamCalendars
+
+	teams, err := parseTeams(os.Getenv("TEAMS"))
+	if err != nil {
+		return nil, err
+	}
+	config.Teams = teams
+
+	if config.ReminderAt != "" {
+		if _, err := time.Parse("15:04", config.ReminderAt); err != nil {
+			return nil, fmt.Errorf("REMINDER_AT must be HH:MM, got %q", config.ReminderAt)
+		}
+	}
+	config.ReminderUsers = parseLogins(os.Getenv("REMINDER_USERS"))
+	if len(config.ReminderUsers) == 0 {
+		for _, team := range slices.Sorted(maps.Keys(teams)) {
+			for _, login := range teams[team] {
+				if !slices.Contains(config.ReminderUsers, login) {
+					config.ReminderUsers = append(config.ReminderUsers, login)
+				}
+			}
+		}
+	}
+	if config.UserEmails, err = parseUserValues("USER_EMAILS", os.Getenv("USER_EMAILS")); err != nil {
+		return nil, err
+	}
+	if config.TelegramChats, err = parseUserValues("TELEGRAM_CHATS", os.Getenv("TELEGRAM_CHATS")); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
 func (c *Config) validate() error {
-	if c.YandexIAMToken == "" {
-		return errors.New("YANDEX_IAM_TOKEN is required")
+	switch c.OrgType {
+	case OrgTypeCloud, OrgType360:
+	default:
+		return fmt.Errorf("YANDEX_ORG_TYPE must be %q or %q, got %q", OrgTypeCloud, OrgType360, c.OrgType)
+	}
+	switch c.AuthScheme {
+	case AuthSchemeIAM:
+		if c.OrgType == OrgType360 {
+			return errors.New("IAM tokens work only with Yandex Cloud organizations, use YANDEX_AUTH_SCHEME=oauth for Yandex 360")
+		}
+		if c.YandexIAMToken == "" && c.YandexSAKeyFile == "" {
+			return errors.New("YANDEX_IAM_TOKEN or YANDEX_SA_KEY_FILE is required")
+		}
+	case AuthSchemeOAuth:
+		if c.YandexOAuthToken == "" {
+			return errors.New("YANDEX_OAUTH_TOKEN is required")
+		}
+	default:
+		return fmt.Errorf("YANDEX_AUTH_SCHEME must be %q or %q, got %q", AuthSchemeIAM, AuthSchemeOAuth, c.AuthScheme)
 	}
 	if c.YandexOrgID == "" {
 		return errors.New("YANDEX_ORG_ID is required")
@@ -37,6 +254,12 @@ func (c *Config) validate() error {
 	if c.TrackerHost == "" {
 		return errors.New("TRACKER_HOST is required")
 	}
+	if u, err := url.Parse(c.TrackerAPIURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("TRACKER_API_URL must be an absolute URL, got %q", c.TrackerAPIURL)
+	}
+	if c.TrackerAPIVersion != "v2" && c.TrackerAPIVersion != "v3" {
+		return fmt.Errorf("TRACKER_API_VERSION must be v2 or v3, got %q", c.TrackerAPIVersion)
+	}
 	return nil
 }
 
@@ -46,3 +269,112 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func parseTeams(value string) (map[string][]string, error) {
+	teams := map[string][]string{}
+	for _, team := range strings.Split(value, ";") {
+		if strings.TrimSpace(team) == "" {
+			continue
+		}
+		name, members, ok := strings.Cut(team, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("TEAMS: invalid team %q, expected name=login1,login2", team)
+		}
+		for _, login := range strings.Split(members, ",") {
+			if login = strings.TrimSpace(login); login != "" {
+				teams[name] = append(teams[name], login)
+			}
+		}
+		if len(teams[name]) == 0 {
+			return nil, fmt.Errorf("TEAMS: team %q has no members", name)
+		}
+	}
+	return teams, nil
+}
+
+func parseUserTimezones(value string) (map[string]*time.Location, error) {
+	timezones := map[string]*time.Location{}
+	for _, user := range strings.Split(value, ";") {
+		if strings.TrimSpace(user) == "" {
+			continue
+		}
+		login, name, ok := strings.Cut(user, "=")
+		login = strings.TrimSpace(login)
+		if !ok || login == "" {
+			return nil, fmt.Errorf("USER_TIMEZONES: invalid entry %q, expected login=Area/City", user)
+		}
+		loc, err := time.LoadLocation(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("USER_TIMEZONES: %s: %w", login, err)
+		}
+		timezones[login] = loc
+	}
+	return timezones, nil
+}
+
+func parseCalendarOverrides(value string) (map[string]string, error) {
+	overrides := map[string]string{}
+	for _, override := range strings.Split(value, ";") {
+		if strings.TrimSpace(override) == "" {
+			continue
+		}
+		name, path, ok := strings.Cut(override, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !ok || name == "" || path == "" {
+			return nil, fmt.Errorf("CALENDAR_OVERRIDES: invalid entry %q, expected name=path", override)
+		}
+		overrides[name] = path
+	}
+	return overrides, nil
+}
+
+func parseTeamCalendars(value string) (map[string][]string, error) {
+	calendars := map[string][]string{}
+	for _, team := range strings.Split(value, ";") {
+		if strings.TrimSpace(team) == "" {
+			continue
+		}
+		name, overrides, ok := strings.Cut(team, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("TEAM_CALENDARS: invalid entry %q, expected team=override1,override2", team)
+		}
+		for _, override := range strings.Split(overrides, ",") {
+			if override = strings.TrimSpace(override); override != "" {
+				calendars[name] = append(calendars[name], override)
+			}
+		}
+		if len(calendars[name]) == 0 {
+			return nil, fmt.Errorf("TEAM_CALENDARS: team %q has no overrides", name)
+		}
+	}
+	return calendars, nil
+}
+
+func parseLogins(value string) []string {
+	var logins []string
+	for _, login := range strings.Split(value, ",") {
+		if login = strings.TrimSpace(login); login != "" {
+			logins = append(logins, login)
+		}
+	}
+	return logins
+}
+
+// parseUserValues разбирает значения по логину: alice=alice@example.com;bob=bob@example.com
+func parseUserValues(key, value string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, user := range strings.Split(value, ";") {
+		if strings.TrimSpace(user) == "" {
+			continue
+		}
+		login, v, ok := strings.Cut(user, "=")
+		login, v = strings.TrimSpace(login), strings.TrimSpace(v)
+		if !ok || login == "" || v == "" {
+			return nil, fmt.Errorf("%s: invalid entry %q, expected login=value", key, user)
+		}
+		values[login] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Config{
+		AuthScheme:        AuthSchemeIAM,
+		OrgType:           OrgTypeCloud,
+		YandexIAMToken:    "token",
+		YandexOrgID:       "org",
+		TrackerHost:       "https://tracker.yandex.ru",
+		TrackerAPIURL:     "https://api.tracker.yandex.net",
+		TrackerAPIVersion: "v3",
+	}
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{"IAM token in cloud organization", func(c *Config) {}, false},
+		{"Service account key instead of IAM token", func(c *Config) { c.YandexIAMToken = ""; c.YandexSAKeyFile = "key.json" }, false},
+		{"OAuth in Yandex 360 organization", func(c *Config) {
+			c.AuthScheme, c.OrgType, c.YandexOAuthToken = AuthSchemeOAuth, OrgType360, "oauth"
+		}, false},
+		{"OAuth in cloud organization", func(c *Config) { c.AuthScheme, c.YandexOAuthToken = AuthSchemeOAuth, "oauth" }, false},
+		{"IAM token in Yandex 360 organization", func(c *Config) { c.OrgType = OrgType360 }, true},
+		{"OAuth without token", func(c *Config) { c.AuthScheme = AuthSchemeOAuth }, true},
+		{"No IAM token", func(c *Config) { c.YandexIAMToken = "" }, true},
+		{"Unknown auth scheme", func(c *Config) { c.AuthScheme = "basic" }, true},
+		{"Unknown organization type", func(c *Config) { c.OrgType = "other" }, true},
+		{"No organization", func(c *Config) { c.YandexOrgID = "" }, true},
+		{"No tracker host", func(c *Config) { c.TrackerHost = "" }, true},
+		{"Local API URL with v2", func(c *Config) { c.TrackerAPIURL, c.TrackerAPIVersion = "http://127.0.0.1:8081", "v2" }, false},
+		{"Relative API URL", func(c *Config) { c.TrackerAPIURL = "api.tracker.yandex.net" }, true},
+		{"Unknown API version", func(c *Config) { c.TrackerAPIVersion = "v1" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			if err := c.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseTeams(t *testing.T) {
+	got, err := parseTeams("backend=alice, bob;frontend=carol;")
+	if err != nil {
+		t.Fatalf("parseTeams() error = %v", err)
+	}
+	want := map[string][]string{"backend": {"alice", "bob"}, "frontend": {"carol"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTeams() = %v, want %v", got, want)
+	}
+
+	for _, value := range []string{"backend", "=alice", "backend= , "} {
+		if _, err := parseTeams(value); err == nil {
+			t.Errorf("parseTeams(%q) error = nil, want error", value)
+		}
+	}
+}
+
+func TestParseUserTimezones(t *testing.T) {
+	got, err := parseUserTimezones("alice=Europe/Berlin; bob = Asia/Yekaterinburg;")
+	if err != nil {
+		t.Fatalf("parseUserTimezones() error = %v", err)
+	}
+	if len(got) != 2 || got["alice"].String() != "Europe/Berlin" || got["bob"].String() != "Asia/Yekaterinburg" {
+		t.Errorf("parseUserTimezones() = %v", got)
+	}
+
+	for _, value := range []string{"alice", "=Europe/Berlin", "alice=Mars/Olympus"} {
+		if _, err := parseUserTimezones(value); err == nil {
+			t.Errorf("parseUserTimezones(%q) error = nil, want error", value)
+		}
+	}
+}
+
+func TestParseCalendars(t *testing.T) {
+	overrides, err := parseCalendarOverrides("by=/etc/by.ics; backend = backend.json;")
+	if err != nil {
+		t.Fatalf("parseCalendarOverrides() error = %v", err)
+	}
+	if want := map[string]string{"by": "/etc/by.ics", "backend": "backend.json"}; !reflect.DeepEqual(overrides, want) {
+		t.Errorf("parseCalendarOverrides() = %v, want %v", overrides, want)
+	}
+	teams, err := parseTeamCalendars("minsk=by, backend")
+	if err != nil {
+		t.Fatalf("parseTeamCalendars() error = %v", err)
+	}
+	if want := map[string][]string{"minsk": {"by", "backend"}}; !reflect.DeepEqual(teams, want) {
+		t.Errorf("parseTeamCalendars() = %v, want %v", teams, want)
+	}
+
+	for _, value := range []string{"by", "=by.ics", "by="} {
+		if _, err := parseCalendarOverrides(value); err == nil {
+			t.Errorf("parseCalendarOverrides(%q) error = nil, want error", value)
+		}
+	}
+	for _, value := range []string{"minsk", "=by", "minsk= , "} {
+		if _, err := parseTeamCalendars(value); err == nil {
+			t.Errorf("parseTeamCalendars(%q) error = nil, want error", value)
+		}
+	}
+}
+
+func TestParseUserValues(t *testing.T) {
+	got, err := parseUserValues("TELEGRAM_CHATS", "alice=123; bob = 456;")
+	if err != nil {
+		t.Fatalf("parseUserValues() error = %v", err)
+	}
+	if want := map[string]string{"alice": "123", "bob": "456"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseUserValues() = %v, want %v", got, want)
+	}
+
+	for _, value := range []string{"alice", "=123", "alice="} {
+		if _, err := parseUserValues("TELEGRAM_CHATS", value); err == nil {
+			t.Errorf("parseUserValues(%q) error = nil, want error", value)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Package humanize форматирует значения для людей: в таблицах, отчетах и напоминаниях.
+package humanize
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration — длительность в часах и минутах: 1h 30m, 45m, 0m
+func Duration(d time.Duration) string {
+	h := int(d / time.Hour)
+	m := int((d % time.Hour) / time.Minute)
+
+	parts := make([]string, 0, 2)
+	if h > 0 {
+		parts = append(parts, strconv.Itoa(h)+"h")
+	}
+
+	if m > 0 {
+		parts = append(parts, strconv.Itoa(m)+"m")
+	}
+
+	if len(parts) == 0 {
+		return "0m"
+	}
+
+	return strings.Join(parts, " ")
+}
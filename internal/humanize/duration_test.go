@@ -0,0 +1,26 @@
+package humanize_test
+
+import (
+	"testing"
+	"time"
+
+	"example.com/tracker/internal/humanize"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{45 * time.Minute, "45m"},
+		{8 * time.Hour, "8h"},
+		{5*time.Hour + 30*time.Minute, "5h 30m"},
+		{30 * time.Second, "0m"},
+	}
+	for _, tt := range tests {
+		if got := humanize.Duration(tt.d); got != tt.want {
+			t.Errorf("Duration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
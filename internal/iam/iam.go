@@ -0,0 +1,195 @@
+// Package iam получает IAM-токены Yandex Cloud по авторизованному ключу сервисного аккаунта.
+package iam
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTokenURL = "https://iam.api.cloud.yandex.net/iam/v1/tokens"
+	// токен обновляется заранее, за refreshMargin до истечения
+	refreshMargin = 10 * time.Minute
+	jwtLifetime   = time.Hour
+)
+
+// ServiceAccountKey — авторизованный ключ сервисного аккаунта
+// (файл, который создает `yc iam key create --output key.json`)
+type ServiceAccountKey struct {
+	ID               string `json:"id"`
+	ServiceAccountID string `json:"service_account_id"`
+	PrivateKey       string `json:"private_key"`
+}
+
+func LoadKey(path string) (ServiceAccountKey, error) {
+	var key ServiceAccountKey
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return key, fmt.Errorf("error reading key file: %w", err)
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return key, fmt.Errorf("error decoding key file: %w", err)
+	}
+	if key.ID == "" || key.ServiceAccountID == "" || key.PrivateKey == "" {
+		return key, errors.New("key file must contain id, service_account_id and private_key")
+	}
+	return key, nil
+}
+
+type Config struct {
+	Key ServiceAccountKey
+	// адрес обмена JWT на IAM-токен, по умолчанию DefaultTokenURL
+	TokenURL   string
+	HTTPClient *http.Client
+}
+
+// TokenSource выдает IAM-токен, кеширует его и обновляет до истечения срока
+type TokenSource struct {
+	config     Config
+	privateKey *rsa.PrivateKey
+	now        func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func NewTokenSource(config Config) (*TokenSource, error) {
+	privateKey, err := parsePrivateKey(config.Key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = DefaultTokenURL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &TokenSource{config: config, privateKey: privateKey, now: time.Now}, nil
+}
+
+// Token возвращает действующий токен, при необходимости получая новый
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Before(s.expiresAt.Add(-refreshMargin)) {
+		return s.token, nil
+	}
+	token, expiresAt, err := s.exchange(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiresAt = token, expiresAt
+	return token, nil
+}
+
+// Invalidate сбрасывает закешированный токен, следующий Token получит новый
+func (s *TokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+func (s *TokenSource) exchange(ctx context.Context) (string, time.Time, error) {
+	jwt, err := s.signedJWT()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	body, err := json.Marshal(map[string]string{"jwt": jwt})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, bytes.NewReader(body))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.config.HTTPClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error requesting iam token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", time.Time{}, fmt.Errorf("error requesting iam token: status code %d: %s", resp.StatusCode, data)
+	}
+
+	var result struct {
+		IAMToken  string    `json:"iamToken"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("error decoding iam token: %w", err)
+	}
+	if result.IAMToken == "" {
+		return "", time.Time{}, errors.New("error requesting iam token: empty token")
+	}
+	return result.IAMToken, result.ExpiresAt, nil
+}
+
+// signedJWT собирает JWT, подписанный ключом сервисного аккаунта (PS256)
+func (s *TokenSource) signedJWT() (string, error) {
+	now := s.now()
+	header, err := json.Marshal(map[string]string{
+		"typ": "JWT",
+		"alg": "PS256",
+		"kid": s.config.Key.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss": s.config.Key.ServiceAccountID,
+		"aud": s.config.TokenURL,
+		"iat": now.Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPSS(rand.Reader, s.privateKey, crypto.SHA256, hash[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		return "", fmt.Errorf("error signing jwt: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func parsePrivateKey(data string) (*rsa.PrivateKey, error) {
+	// pem.Decode пропускает строку-предупреждение, которую yc пишет перед ключом
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("private key is not in PEM format")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if rsaKey, pkcs1Err := x509.ParsePKCS1PrivateKey(block.Bytes); pkcs1Err == nil {
+			return rsaKey, nil
+		}
+		return nil, fmt.Errorf("error parsing private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
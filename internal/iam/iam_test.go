@@ -0,0 +1,147 @@
+package iam
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestKey(t *testing.T) (ServiceAccountKey, *rsa.PrivateKey) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := "PLEASE DO NOT REMOVE THIS LINE! Yandex.Cloud CLI Key ID <key-id>\n" +
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	return ServiceAccountKey{ID: "key-id", ServiceAccountID: "sa-id", PrivateKey: pemKey}, privateKey
+}
+
+// newTokenServer проверяет подпись JWT и выдает токены token-1, token-2, ...
+func newTokenServer(t *testing.T, publicKey *rsa.PublicKey, expiresIn time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			JWT string `json:"jwt"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding token request: %v", err)
+		}
+		parts := strings.Split(body.JWT, ".")
+		if len(parts) != 3 {
+			t.Fatalf("jwt has %d parts", len(parts))
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPSS(publicKey, crypto.SHA256, hash[:], signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+			t.Errorf("jwt signature: %v", err)
+		}
+		header, _ := base64.RawURLEncoding.DecodeString(parts[0])
+		if !strings.Contains(string(header), `"kid":"key-id"`) || !strings.Contains(string(header), `"alg":"PS256"`) {
+			t.Errorf("jwt header = %s", header)
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		if !strings.Contains(string(claims), `"iss":"sa-id"`) {
+			t.Errorf("jwt claims = %s", claims)
+		}
+
+		n := calls.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{
+			"iamToken":  "token-" + string(rune('0'+n)),
+			"expiresAt": time.Now().Add(expiresIn),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestTokenSource(t *testing.T) {
+	key, privateKey := newTestKey(t)
+	server, calls := newTokenServer(t, &privateKey.PublicKey, 12*time.Hour)
+
+	source, err := NewTokenSource(Config{Key: key, TokenURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewTokenSource() error = %v", err)
+	}
+
+	for range 3 {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if token != "token-1" {
+			t.Errorf("Token() = %v, want cached token-1", token)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("token requests = %d, want 1", calls.Load())
+	}
+
+	source.Invalidate()
+	if token, _ := source.Token(context.Background()); token != "token-2" {
+		t.Errorf("Token() after Invalidate = %v, want token-2", token)
+	}
+
+	// за несколько минут до истечения токен обновляется заранее
+	source.now = func() time.Time { return time.Now().Add(12*time.Hour - refreshMargin/2) }
+	if token, _ := source.Token(context.Background()); token != "token-3" {
+		t.Errorf("Token() before expiry = %v, want token-3", token)
+	}
+}
+
+func TestTokenSourceError(t *testing.T) {
+	key, _ := newTestKey(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	source, err := NewTokenSource(Config{Key: key, TokenURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Token(context.Background()); err == nil {
+		t.Error("Token() error = nil, want error")
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	key, _ := newTestKey(t)
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "key.json")
+	data, _ := json.Marshal(key)
+	os.WriteFile(valid, data, 0o600)
+	if got, err := LoadKey(valid); err != nil || got.ServiceAccountID != "sa-id" {
+		t.Errorf("LoadKey() = %v, %v", got, err)
+	}
+
+	incomplete := filepath.Join(dir, "incomplete.json")
+	os.WriteFile(incomplete, []byte(`{"id":"key-id"}`), 0o600)
+	if _, err := LoadKey(incomplete); err == nil {
+		t.Error("LoadKey() with incomplete key: error = nil")
+	}
+
+	if _, err := NewTokenSource(Config{Key: ServiceAccountKey{PrivateKey: "not a key"}}); err == nil {
+		t.Error("NewTokenSource() with invalid key: error = nil")
+	}
+}
@@ -0,0 +1,179 @@
+package reminder
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"example.com/tracker/internal/client"
+)
+
+// SMTPNotifier отправляет напоминание письмом
+type SMTPNotifier struct {
+	// адрес сервера, smtp.example.com:587
+	Addr string
+	From string
+	// логин и пароль; пустые — без авторизации
+	Username, Password string
+	// адреса участников по логину; без адреса письмо уходит на login@Domain
+	Emails map[string]string
+	Domain string
+	// предел на соединение и отправку письма; 0 — defaultSMTPTimeout
+	Timeout time.Duration
+}
+
+const defaultSMTPTimeout = 30 * time.Second
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification Notification) error {
+	to := n.Emails[notification.Login]
+	if to == "" && n.Domain != "" {
+		to = notification.Login + "@" + n.Domain
+	}
+	if to == "" {
+		return fmt.Errorf("no email for %s", notification.Login)
+	}
+	msg := strings.Join([]string{
+		"From: " + n.From,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", "Списание времени за "+notification.Date),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		notification.Text(),
+	}, "\r\n")
+	ctx, cancel := context.WithTimeout(ctx, cmp.Or(n.Timeout, defaultSMTPTimeout))
+	defer cancel()
+	if err := n.send(ctx, to, []byte(msg)); err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+	return nil
+}
+
+// send — smtp.SendMail, но с ctx: соединение и весь обмен ограничены
+// его сроком, отмена ctx прерывает отправку
+func (n *SMTPNotifier) send(ctx context.Context, to string, msg []byte) error {
+	host, _, _ := strings.Cut(n.Addr, ":")
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.Username, n.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// WebhookNotifier отправляет напоминание POST-запросом с JSON:
+// поля Notification и text — готовый текст
+type WebhookNotifier struct {
+	URL string
+	// nil — клиент без интерсепторов
+	Client *client.Client
+}
+
+type webhookPayload struct {
+	Notification
+	Text string `json:"text"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, n.Client, n.URL, webhookPayload{notification, notification.Text()}, nil)
+}
+
+// TelegramNotifier отправляет напоминание через Bot API: POST {APIURL}/bot{Token}/sendMessage
+type TelegramNotifier struct {
+	Token string
+	// адрес Bot API; пустой — https://api.telegram.org
+	APIURL string
+	// чаты участников по логину
+	ChatIDs map[string]string
+	Client  *client.Client
+}
+
+func (n *TelegramNotifier) Notify(ctx context.Context, notification Notification) error {
+	if n.Token == "" {
+		return errors.New("telegram bot token is required")
+	}
+	chatID, ok := n.ChatIDs[notification.Login]
+	if !ok {
+		return fmt.Errorf("no telegram chat for %s", notification.Login)
+	}
+	url := strings.TrimSuffix(cmp.Or(n.APIURL, "https://api.telegram.org"), "/") + "/bot" + n.Token + "/sendMessage"
+	var resp struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	body := map[string]string{"chat_id": chatID, "text": notification.Text()}
+	err := postJSON(ctx, n.Client, url, body, &resp)
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && json.Unmarshal(apiErr.Body, &resp) == nil && resp.Description != "" {
+		return fmt.Errorf("error sending telegram message to %s: status %d: %s", notification.Login, apiErr.StatusCode, resp.Description)
+	}
+	if err != nil {
+		// токен бота — часть адреса запроса, в лог он попадать не должен
+		return fmt.Errorf("error sending telegram message to %s: %s", notification.Login, strings.ReplaceAll(err.Error(), n.Token, "***"))
+	}
+	if !resp.OK {
+		return fmt.Errorf("error sending telegram message to %s: %s", notification.Login, resp.Description)
+	}
+	return nil
+}
+
+func postJSON(ctx context.Context, c *client.Client, url string, body, response any) error {
+	if c == nil {
+		c = client.New(nil)
+	}
+	req, err := c.NewJSONRequest(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Do(req, response); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package reminder_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/tracker/internal/reminder"
+)
+
+var testNotification = reminder.Notification{Login: "bob", Date: "2025-05-06", Logged: 5*time.Hour + 30*time.Minute, Norm: 8 * time.Hour}
+
+// smtpServer — SMTP-сервер на локальном порту, который принимает одно письмо
+type smtpServer struct {
+	addr string
+	mail chan string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	s := &smtpServer{addr: l.Addr().String(), mail: make(chan string, 1)}
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		c := textproto.NewConn(conn)
+		c.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := c.ReadLine()
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line + " ")[0]); cmd {
+			case "EHLO", "HELO":
+				c.PrintfLine("250 localhost")
+			case "DATA":
+				c.PrintfLine("354 go ahead")
+				data, _ := c.ReadDotLines()
+				s.mail <- strings.Join(data, "\n")
+				c.PrintfLine("250 ok")
+			case "QUIT":
+				c.PrintfLine("221 bye")
+				return
+			default:
+				c.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return s
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := newSMTPServer(t)
+	n := &reminder.SMTPNotifier{Addr: server.addr, From: "tracker@example.com", Domain: "example.com"}
+	if err := n.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	mail := <-server.mail
+	subject := mime.QEncoding.Encode("utf-8", "Списание времени за 2025-05-06")
+	if !strings.Contains(mail, "To: bob@example.com") || !strings.Contains(mail, "Subject: "+subject) ||
+		!strings.Contains(mail, "bob, за 2025-05-06 списано 5h 30m из 8h.") {
+		t.Errorf("mail = %s", mail)
+	}
+
+	if err := (&reminder.SMTPNotifier{Addr: server.addr}).Notify(context.Background(), testNotification); err == nil {
+		t.Error("Notify() without email error = nil, want error")
+	}
+}
+
+func TestSMTPNotifierTimeout(t *testing.T) {
+	// сервер принимает соединение и молчит
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			// держит соединение, пока клиент его не закроет
+			go func() {
+				defer conn.Close()
+				io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+
+	n := &reminder.SMTPNotifier{Addr: l.Addr().String(), From: "tracker@example.com", Domain: "example.com", Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	if err := n.Notify(context.Background(), testNotification); err == nil {
+		t.Error("Notify() to silent server error = nil, want error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Notify() took %v, want timeout", elapsed)
+	}
+
+	// отмена ctx прерывает отправку раньше срока
+	n.Timeout = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := n.Notify(ctx, testNotification); err == nil {
+		t.Error("Notify() with canceled context error = nil, want error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Notify() took %v, want cancellation", elapsed)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/hooks/worklog" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil || r.Method != http.MethodPost {
+			http.Error(w, "bad request", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	n := &reminder.WebhookNotifier{URL: server.URL + "/hooks/worklog"}
+	if err := n.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got["login"] != "bob" || got["date"] != "2025-05-06" || got["text"] != testNotification.Text() {
+		t.Errorf("payload = %v", got)
+	}
+
+	n.URL = server.URL + "/missing"
+	if err := n.Notify(context.Background(), testNotification); err == nil {
+		t.Error("Notify() to failing webhook error = nil, want error")
+	}
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	var path string
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&got)
+		if got["chat_id"] != "42" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok": false, "description": "Bad Request: chat not found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok": true, "result": {"message_id": 1}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	n := &reminder.TelegramNotifier{Token: "123:secret", APIURL: server.URL, ChatIDs: map[string]string{"bob": "42", "carol": "7"}}
+	if err := n.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if path != "/bot123:secret/sendMessage" || got["text"] != testNotification.Text() {
+		t.Errorf("path = %s, body = %v", path, got)
+	}
+
+	carol := testNotification
+	carol.Login = "carol"
+	err := n.Notify(context.Background(), carol)
+	if err == nil || !strings.Contains(err.Error(), "chat not found") || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Notify() error = %v, want telegram description without the token", err)
+	}
+	carol.Login = "dave"
+	if err := n.Notify(context.Background(), carol); err == nil {
+		t.Error("Notify() without chat error = nil, want error")
+	}
+}
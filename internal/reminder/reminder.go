@@ -0,0 +1,199 @@
+// Package reminder напоминает участникам, которые не списали время за рабочий
+// день: в заданное время проверяет записи за сегодня и отправляет уведомления
+// тем, у кого меньше нормы дня.
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"example.com/tracker/internal/calendar"
+	"example.com/tracker/internal/humanize"
+	"example.com/tracker/internal/tracker"
+	"github.com/AianaM/durationiso8601"
+	"github.com/AianaM/timefns"
+)
+
+// Notification — напоминание участнику, который списал за день меньше нормы
+type Notification struct {
+	Login  string        `json:"login"`
+	Date   string        `json:"date"`
+	Logged time.Duration `json:"logged"`
+	Norm   time.Duration `json:"norm"`
+	// отчет участника за день; пустая, если адрес сервера не задан
+	Link string `json:"link,omitempty"`
+}
+
+// Text — текст напоминания для почты и мессенджеров
+func (n Notification) Text() string {
+	text := fmt.Sprintf("%s, за %s списано %s из %s.", n.Login, n.Date, humanize.Duration(n.Logged), humanize.Duration(n.Norm))
+	if n.Link != "" {
+		text += " Отчет: " + n.Link
+	}
+	return text
+}
+
+// Notifier доставляет напоминание: почтой, в вебхук или в мессенджер
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+type Config struct {
+	// логины участников, которым отправляются напоминания
+	Users []string
+	// время проверки, 18:00
+	At string
+	// часовой пояс участника: в нем считаются At и день проверки;
+	// nil — time.Local для всех
+	Location func(login string) *time.Location
+	// календарь участника: в нерабочий день напоминания нет
+	Calendar func(login string) *calendar.Calendar
+	// отклонение от нормы, которое не считается недоработкой
+	Tolerance time.Duration
+	// записи автора, созданные в период, например TrackerClient.GetWorklog
+	GetWorklog func(createdBy string, createdAt timefns.TimeSpan) ([]tracker.Worklog, error)
+	// каждое напоминание отправляется через все уведомители
+	Notifiers []Notifier
+	// адрес сервера для ссылки на отчет, https://tracker.example.com
+	BaseURL string
+}
+
+type Scheduler struct {
+	config Config
+	// часы и минуты проверки
+	hour, minute int
+}
+
+func New(config Config) (*Scheduler, error) {
+	at, err := time.Parse("15:04", config.At)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing reminder time: %w", err)
+	}
+	if config.Location == nil {
+		config.Location = func(string) *time.Location { return time.Local }
+	}
+	if config.Calendar == nil || config.GetWorklog == nil {
+		return nil, errors.New("reminder calendar and worklog source are required")
+	}
+	if len(config.Notifiers) == 0 {
+		return nil, errors.New("no reminder notifiers configured")
+	}
+	return &Scheduler{config: config, hour: at.Hour(), minute: at.Minute()}, nil
+}
+
+// Next — ближайшее после now время проверки хотя бы одного участника
+func (s *Scheduler) Next(now time.Time) time.Time {
+	if len(s.config.Users) == 0 {
+		// без участников — по поясу по умолчанию
+		return s.next(now, s.config.Location(""))
+	}
+	var next time.Time
+	for _, login := range s.config.Users {
+		if t := s.next(now, s.config.Location(login)); next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+	return next
+}
+
+// next — ближайшее после now время At в поясе loc
+func (s *Scheduler) next(now time.Time, loc *time.Location) time.Time {
+	now = now.In(loc)
+	next := time.Date(now.Year(), now.Month(), now.Day(), s.hour, s.minute, 0, 0, loc)
+	if !next.After(now) {
+		next = time.Date(now.Year(), now.Month(), now.Day()+1, s.hour, s.minute, 0, 0, loc)
+	}
+	return next
+}
+
+// Run проверяет записи каждый день в заданное время по поясу каждого
+// участника, пока ctx не отменен. Рабочий ли день, решает календарь участника.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		next := s.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := s.Check(ctx, next); err != nil {
+			log.Println("Error sending reminders:", err)
+		}
+	}
+}
+
+// Check в момент at проверяет участников, у которых по их поясу наступило
+// время проверки, за их текущий день и напоминает тем, кто списал меньше
+// нормы. Ошибка одного участника не мешает остальным.
+func (s *Scheduler) Check(ctx context.Context, at time.Time) error {
+	var errs []error
+	for _, login := range s.config.Users {
+		loc := s.config.Location(login)
+		// время проверки участника — первое At после момента чуть раньше at
+		if !s.next(at.Add(-time.Nanosecond), loc).Equal(at) {
+			continue
+		}
+		date := at.In(loc)
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+		cal := s.config.Calendar(login)
+		norm := cal.WorkingHours(day)
+		if !cal.IsWorkingDay(day) || norm == 0 {
+			continue
+		}
+		logged, err := s.logged(login, day)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", login, err))
+			continue
+		}
+		if logged >= norm-s.config.Tolerance {
+			continue
+		}
+		n := Notification{Login: login, Date: day.Format(time.DateOnly), Logged: logged, Norm: norm, Link: s.link(login, day)}
+		for _, notifier := range s.config.Notifiers {
+			if err := notifier.Notify(ctx, n); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", login, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// logged — сколько участник списал за работу в день day
+func (s *Scheduler) logged(login string, day time.Time) (time.Duration, error) {
+	span := timefns.TimeSpan{Start: day, End: day.AddDate(0, 0, 1)}
+	worklogs, err := s.config.GetWorklog(login, tracker.StartWindow(span))
+	if err != nil {
+		return 0, fmt.Errorf("error getting worklogs: %w", err)
+	}
+	var logged time.Duration
+	for _, w := range tracker.StartedIn(worklogs, span) {
+		start, err := timefns.Parse(w.Start)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing date: %w", err)
+		}
+		d, err := durationiso8601.ParseDuration(start, w.Duration)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing duration: %w", err)
+		}
+		logged += d
+	}
+	return logged, nil
+}
+
+func (s *Scheduler) link(login string, day time.Time) string {
+	if s.config.BaseURL == "" {
+		return ""
+	}
+	path, err := url.JoinPath(s.config.BaseURL, "worklog", login, "from", day.Format(time.DateOnly), "to", day.AddDate(0, 0, 1).Format(time.DateOnly))
+	if err != nil {
+		return ""
+	}
+	// ссылка показывает записи по дню работы в поясе участника, как их считает напоминание
+	return path + "?filterBy=start&tz=" + url.QueryEscape(day.Location().String())
+}
@@ -0,0 +1,189 @@
+package reminder_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/tracker/internal/calendar"
+	"example.com/tracker/internal/reminder"
+	"example.com/tracker/internal/tracker"
+	"example.com/tracker/internal/tracker/trackertest"
+	"github.com/AianaM/timefns"
+)
+
+// recorder — уведомитель, который запоминает напоминания
+type recorder struct {
+	notifications []reminder.Notification
+}
+
+func (r *recorder) Notify(_ context.Context, n reminder.Notification) error {
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+// in — один пояс для всех участников
+func in(loc *time.Location) func(string) *time.Location {
+	return func(string) *time.Location { return loc }
+}
+
+func TestCheck(t *testing.T) {
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	server := trackertest.NewServer()
+	t.Cleanup(server.Close)
+	user := func(login string) tracker.User { return tracker.User{Id: login, Display: login} }
+	server.Seed(
+		tracker.Worklog{ID: 1, Issue: tracker.Issue{Key: "TEST-1"}, CreatedBy: user("alice"),
+			CreatedAt: "2025-05-06T18:00:00.000+0300", Start: "2025-05-06T10:00:00.000+0300", Duration: "PT8H"},
+		tracker.Worklog{ID: 2, Issue: tracker.Issue{Key: "TEST-1"}, CreatedBy: user("bob"),
+			CreatedAt: "2025-05-06T18:00:00.000+0300", Start: "2025-05-06T10:00:00.000+0300", Duration: "PT5H30M"},
+		// время за вчера, внесенное сегодня, не засчитывается
+		tracker.Worklog{ID: 3, Issue: tracker.Issue{Key: "TEST-1"}, CreatedBy: user("bob"),
+			CreatedAt: "2025-05-06T18:00:00.000+0300", Start: "2025-05-05T10:00:00.000+0300", Duration: "PT2H"},
+	)
+	ru := calendar.New(8*time.Hour, calendar.Day{Date: "2025-05-08", Kind: calendar.Holiday})
+	// у команды carol 6 мая — выходной
+	team := ru.With(calendar.Day{Date: "2025-05-06", Kind: calendar.Holiday})
+	notifications := &recorder{}
+	s, err := reminder.New(reminder.Config{
+		Users:    []string{"alice", "bob", "carol"},
+		At:       "18:30",
+		Location: in(moscow),
+		Calendar: func(login string) *calendar.Calendar {
+			if login == "carol" {
+				return team
+			}
+			return ru
+		},
+		Tolerance:  15 * time.Minute,
+		GetWorklog: server.NewTrackerClient().GetWorklog,
+		Notifiers:  []reminder.Notifier{notifications},
+		BaseURL:    "https://tracker.example.com/",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		date time.Time
+		want []reminder.Notification
+	}{
+		{"Working day", time.Date(2025, 5, 6, 18, 30, 0, 0, moscow), []reminder.Notification{{
+			Login: "bob", Date: "2025-05-06", Logged: 5*time.Hour + 30*time.Minute, Norm: 8 * time.Hour,
+			Link: "https://tracker.example.com/worklog/bob/from/2025-05-06/to/2025-05-07?filterBy=start&tz=Europe%2FMoscow",
+		}}},
+		{"Holiday", time.Date(2025, 5, 8, 18, 30, 0, 0, moscow), nil},
+		{"Weekend", time.Date(2025, 5, 10, 18, 30, 0, 0, moscow), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifications.notifications = nil
+			if err := s.Check(context.Background(), tt.date); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if !slices.Equal(notifications.notifications, tt.want) {
+				t.Errorf("notifications = %+v, want %+v", notifications.notifications, tt.want)
+			}
+		})
+	}
+
+	server.InjectFailure(trackertest.Failure{Status: http.StatusForbidden})
+	if err := s.Check(context.Background(), time.Date(2025, 5, 7, 18, 30, 0, 0, moscow)); err == nil || !strings.Contains(err.Error(), "alice") {
+		t.Errorf("Check() with Tracker failure error = %v, want error for alice", err)
+	}
+}
+
+func TestCheckTimezones(t *testing.T) {
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	newYork, _ := time.LoadLocation("America/New_York")
+	server := trackertest.NewServer()
+	t.Cleanup(server.Close)
+	// bob в Нью-Йорке работал вечером 6 мая, по Москве это уже 7 мая
+	server.Seed(tracker.Worklog{ID: 1, Issue: tracker.Issue{Key: "TEST-1"}, CreatedBy: tracker.User{Id: "bob", Display: "bob"},
+		CreatedAt: "2025-05-06T18:00:00.000-0400", Start: "2025-05-06T17:00:00.000-0400", Duration: "PT7H"})
+	notifications := &recorder{}
+	s, err := reminder.New(reminder.Config{
+		Users: []string{"alice", "bob"},
+		At:    "18:30",
+		Location: func(login string) *time.Location {
+			if login == "bob" {
+				return newYork
+			}
+			return moscow
+		},
+		Calendar:   func(string) *calendar.Calendar { return calendar.New(8 * time.Hour) },
+		GetWorklog: server.NewTrackerClient().GetWorklog,
+		Notifiers:  []reminder.Notifier{notifications},
+		BaseURL:    "https://tracker.example.com",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		// время проверки после now
+		next time.Time
+		want []reminder.Notification
+	}{
+		{"Moscow", time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC), time.Date(2025, 5, 6, 18, 30, 0, 0, moscow), []reminder.Notification{{
+			Login: "alice", Date: "2025-05-06", Norm: 8 * time.Hour,
+			Link: "https://tracker.example.com/worklog/alice/from/2025-05-06/to/2025-05-07?filterBy=start&tz=Europe%2FMoscow",
+		}}},
+		{"New York", time.Date(2025, 5, 6, 18, 0, 0, 0, time.UTC), time.Date(2025, 5, 6, 18, 30, 0, 0, newYork), []reminder.Notification{{
+			Login: "bob", Date: "2025-05-06", Logged: 7 * time.Hour, Norm: 8 * time.Hour,
+			Link: "https://tracker.example.com/worklog/bob/from/2025-05-06/to/2025-05-07?filterBy=start&tz=America%2FNew_York",
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifications.notifications = nil
+			next := s.Next(tt.now)
+			if !next.Equal(tt.next) {
+				t.Fatalf("Next(%v) = %v, want %v", tt.now, next, tt.next)
+			}
+			if err := s.Check(context.Background(), next); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if !slices.Equal(notifications.notifications, tt.want) {
+				t.Errorf("notifications = %+v, want %+v", notifications.notifications, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	s, err := reminder.New(reminder.Config{
+		At:         "18:00",
+		Location:   in(moscow),
+		Calendar:   func(string) *calendar.Calendar { return calendar.New(8 * time.Hour) },
+		GetWorklog: func(string, timefns.TimeSpan) ([]tracker.Worklog, error) { return nil, nil },
+		Notifiers:  []reminder.Notifier{&recorder{}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	tests := []struct {
+		now, want time.Time
+	}{
+		{time.Date(2025, 5, 6, 9, 0, 0, 0, moscow), time.Date(2025, 5, 6, 18, 0, 0, 0, moscow)},
+		{time.Date(2025, 5, 6, 18, 0, 0, 0, moscow), time.Date(2025, 5, 7, 18, 0, 0, 0, moscow)},
+		// 20:00 UTC — уже 23:00 по Москве
+		{time.Date(2025, 5, 6, 20, 0, 0, 0, time.UTC), time.Date(2025, 5, 7, 18, 0, 0, 0, moscow)},
+	}
+	for _, tt := range tests {
+		if got := s.Next(tt.now); !got.Equal(tt.want) {
+			t.Errorf("Next(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+
+	if _, err := reminder.New(reminder.Config{At: "6pm"}); err == nil {
+		t.Error("New() with invalid time error = nil, want error")
+	}
+}
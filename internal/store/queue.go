@@ -0,0 +1,119 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"example.com/tracker/internal/tracker"
+)
+
+type OperationKind string
+
+const (
+	OperationCreate OperationKind = "create"
+	OperationUpdate OperationKind = "update"
+	OperationDelete OperationKind = "delete"
+)
+
+// Operation — изменение, сделанное без связи с Трекером и ожидающее отправки
+type Operation struct {
+	ID        int           `json:"id"`
+	Kind      OperationKind `json:"kind"`
+	IssueKey  string        `json:"issueKey"`
+	WorklogID int           `json:"worklogId,omitempty"`
+	// версия записи, которую видел пользователь; если в Трекере
+	// запись успели изменить, изменение не применяется
+	Version   int           `json:"version,omitempty"`
+	Start     time.Time     `json:"start,omitzero"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	Comment   string        `json:"comment"`
+	CreatedAt time.Time     `json:"createdAt"`
+	// причина, по которой изменение не удалось отправить; такие
+	// изменения не отправляются повторно, пока их не отменят
+	Error string `json:"error,omitempty"`
+}
+
+// Enqueue ставит изменение в очередь и сразу применяет его к кэшу.
+// Повторное изменение той же записи заменяет еще не отправленное,
+// чтобы при отправке не возник конфликт версий с самим собой.
+// Если очередь не удалось сохранить, кэш и очередь остаются прежними.
+func (s *Store) Enqueue(op Operation) (Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// изменение, о котором пользователь получил ошибку, не должно уйти в Трекер позже
+	var snapshot []byte
+	if s.path != "" {
+		var err error
+		if snapshot, err = json.Marshal(s.data); err != nil {
+			return Operation{}, fmt.Errorf("error encoding store: %w", err)
+		}
+	}
+
+	op.CreatedAt = time.Now()
+	i := slices.IndexFunc(s.data.Queue, func(v Operation) bool {
+		return op.Kind != OperationCreate && v.Kind == OperationUpdate && v.WorklogID == op.WorklogID && v.Error == ""
+	})
+	if i >= 0 {
+		op.ID = s.data.Queue[i].ID
+		op.Version = s.data.Queue[i].Version
+		s.data.Queue[i] = op
+	} else {
+		s.data.NextOperationID++
+		op.ID = s.data.NextOperationID
+		s.data.Queue = append(s.data.Queue, op)
+	}
+
+	if user, key, i := s.find(op.WorklogID); user != "" && op.Kind != OperationCreate {
+		day := s.data.Users[user][key]
+		if op.Kind == OperationDelete {
+			day.Worklogs = slices.Delete(day.Worklogs, i, i+1)
+		} else {
+			day.Worklogs[i].Duration = tracker.FormatDuration(op.Duration)
+			day.Worklogs[i].Comment = op.Comment
+		}
+	}
+	if err := s.save(); err != nil {
+		var restored data
+		if json.Unmarshal(snapshot, &restored) == nil {
+			s.data = restored
+		}
+		return Operation{}, err
+	}
+	return op, nil
+}
+
+// Queue возвращает изменения в порядке их создания
+func (s *Store) Queue() []Operation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.data.Queue)
+}
+
+// Done убирает отправленное или отмененное изменение из очереди
+func (s *Store) Done(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.data.Queue, func(v Operation) bool { return v.ID == id })
+	if i < 0 {
+		return nil
+	}
+	s.data.Queue = slices.Delete(s.data.Queue, i, i+1)
+	return s.save()
+}
+
+// Fail запоминает, почему изменение не удалось отправить
+func (s *Store) Fail(id int, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.data.Queue, func(v Operation) bool { return v.ID == id })
+	if i < 0 {
+		return nil
+	}
+	s.data.Queue[i].Error = reason
+	return s.save()
+}
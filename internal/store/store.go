@@ -0,0 +1,234 @@
+// Package store — локальный кэш записей о затраченном времени в JSON-файле.
+// Записи хранятся по автору и дню создания (createdAt, местное время);
+// для каждого дня запоминается время последней синхронизации с Трекером.
+// Изменения, сделанные без связи с Трекером, копятся в очереди до отправки.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"example.com/tracker/internal/tracker"
+	"github.com/AianaM/timefns"
+)
+
+// Day — записи автора, созданные в один день
+type Day struct {
+	// время последней синхронизации; нулевое — день не загружался целиком
+	SyncedAt time.Time         `json:"syncedAt"`
+	Worklogs []tracker.Worklog `json:"worklogs"`
+}
+
+type data struct {
+	// автор → день (2006-01-02) → записи
+	Users map[string]map[string]*Day `json:"users"`
+	// изменения, сделанные без связи с Трекером
+	Queue           []Operation `json:"queue"`
+	NextOperationID int         `json:"nextOperationId"`
+}
+
+type Store struct {
+	// пустой путь — кэш только в памяти
+	path string
+
+	mu   sync.Mutex
+	data data
+}
+
+// Open открывает кэш из файла path; если файла нет, он будет создан при первой записи
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, data: data{Users: map[string]map[string]*Day{}}}
+	if path == "" {
+		return s, nil
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading store: %w", err)
+	}
+	if err := json.Unmarshal(content, &s.data); err != nil {
+		return nil, fmt.Errorf("error decoding store %s: %w", path, err)
+	}
+	if s.data.Users == nil {
+		s.data.Users = map[string]map[string]*Day{}
+	}
+	return s, nil
+}
+
+// Days расширяет период до целых дней в местном времени:
+// кэш загружается и помечается синхронизированным только по дням
+func Days(span timefns.TimeSpan) timefns.TimeSpan {
+	start := dayStart(span.Start)
+	end := dayStart(span.End)
+	if end.Before(span.End) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return timefns.TimeSpan{Start: start, End: end}
+}
+
+func dayStart(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func dayKeys(span timefns.TimeSpan) []string {
+	days := Days(span)
+	keys := []string{}
+	for day := days.Start; day.Before(days.End); day = day.AddDate(0, 0, 1) {
+		keys = append(keys, day.Format(time.DateOnly))
+	}
+	return keys
+}
+
+// Get возвращает записи автора, созданные в период createdAt, и время самой
+// старой синхронизации среди дней периода. ok == false, если хотя бы один
+// день еще не загружался.
+func (s *Store) Get(createdBy string, createdAt timefns.TimeSpan) (worklogs []tracker.Worklog, syncedAt time.Time, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days := s.data.Users[createdBy]
+	worklogs = []tracker.Worklog{}
+	for _, key := range dayKeys(createdAt) {
+		day := days[key]
+		if day == nil || day.SyncedAt.IsZero() {
+			return nil, time.Time{}, false
+		}
+		if syncedAt.IsZero() || day.SyncedAt.Before(syncedAt) {
+			syncedAt = day.SyncedAt
+		}
+		for _, w := range day.Worklogs {
+			created, err := timefns.Parse(w.CreatedAt)
+			if err != nil || created.Before(createdAt.Start) || created.After(createdAt.End) {
+				continue
+			}
+			worklogs = append(worklogs, w)
+		}
+	}
+	return worklogs, syncedAt, true
+}
+
+// Replace заменяет дни периода createdAt загруженными записями:
+// записи, удаленные в Трекере, пропадают и из кэша
+func (s *Store) Replace(createdBy string, createdAt timefns.TimeSpan, worklogs []tracker.Worklog, syncedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days := s.userDays(createdBy)
+	for _, key := range dayKeys(createdAt) {
+		days[key] = &Day{SyncedAt: syncedAt}
+	}
+	for _, w := range worklogs {
+		s.upsert(createdBy, w)
+	}
+	return s.save()
+}
+
+// Put сохраняет запись, измененную через приложение. Новая запись попадает
+// к тому автору, у которого уже есть записи того же пользователя Трекера.
+func (s *Store) Put(w tracker.Worklog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, _, _ := s.find(w.ID); user != "" {
+		s.upsert(user, w)
+		return s.save()
+	}
+	for user, days := range s.data.Users {
+		for _, day := range days {
+			if slices.ContainsFunc(day.Worklogs, func(v tracker.Worklog) bool { return v.CreatedBy.Id == w.CreatedBy.Id }) {
+				s.upsert(user, w)
+				return s.save()
+			}
+		}
+	}
+	return nil
+}
+
+// Delete удаляет запись из кэша
+func (s *Store) Delete(issueKey string, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, key, i := s.find(id)
+	if user == "" || s.data.Users[user][key].Worklogs[i].Issue.Key != issueKey {
+		return nil
+	}
+	day := s.data.Users[user][key]
+	day.Worklogs = slices.Delete(day.Worklogs, i, i+1)
+	return s.save()
+}
+
+func (s *Store) userDays(createdBy string) map[string]*Day {
+	days := s.data.Users[createdBy]
+	if days == nil {
+		days = map[string]*Day{}
+		s.data.Users[createdBy] = days
+	}
+	return days
+}
+
+// find ищет запись по id: автор, день и индекс в дне
+func (s *Store) find(id int) (user, key string, index int) {
+	for user, days := range s.data.Users {
+		for key, day := range days {
+			if i := slices.IndexFunc(day.Worklogs, func(w tracker.Worklog) bool { return w.ID == id }); i >= 0 {
+				return user, key, i
+			}
+		}
+	}
+	return "", "", -1
+}
+
+// upsert кладет запись в день ее создания; более старая версия не заменяет новую
+func (s *Store) upsert(createdBy string, w tracker.Worklog) {
+	created, err := timefns.Parse(w.CreatedAt)
+	if err != nil {
+		return
+	}
+	key := dayStart(created).Format(time.DateOnly)
+	days := s.userDays(createdBy)
+	day := days[key]
+	if day == nil {
+		day = &Day{}
+		days[key] = day
+	}
+	if i := slices.IndexFunc(day.Worklogs, func(v tracker.Worklog) bool { return v.ID == w.ID }); i >= 0 {
+		if day.Worklogs[i].Version <= w.Version {
+			day.Worklogs[i] = w
+		}
+		return
+	}
+	day.Worklogs = append(day.Worklogs, w)
+}
+
+// save записывает кэш во временный файл и переименовывает его,
+// чтобы при сбое не остался наполовину записанный файл
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	content, err := json.Marshal(s.data)
+	if err != nil {
+		return fmt.Errorf("error encoding store: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("error creating store directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return fmt.Errorf("error writing store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("error writing store: %w", err)
+	}
+	return nil
+}
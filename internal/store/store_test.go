@@ -0,0 +1,168 @@
+package store_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/tracker/internal/store"
+	"example.com/tracker/internal/tracker"
+	"github.com/AianaM/timefns"
+)
+
+func worklog(id, version int, createdAt time.Time) tracker.Worklog {
+	return tracker.Worklog{
+		ID:        id,
+		Version:   version,
+		Issue:     tracker.Issue{Key: "TEST-1"},
+		CreatedBy: tracker.User{Id: "1"},
+		CreatedAt: createdAt.Format(timefns.ISO8601n),
+		Start:     createdAt.Format(timefns.ISO8601n),
+		Duration:  "PT1H",
+	}
+}
+
+func ids(worklogs []tracker.Worklog) string {
+	var ids []string
+	for _, w := range worklogs {
+		ids = append(ids, fmt.Sprintf("%d@%d", w.ID, w.Version))
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "worklogs.json")
+	s, err := store.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	day := time.Date(2025, 5, 5, 0, 0, 0, 0, time.Local)
+	week := timefns.TimeSpan{Start: day, End: day.AddDate(0, 0, 7)}
+	synced := day.AddDate(0, 0, 3)
+
+	if _, _, ok := s.Get("alice", week); ok {
+		t.Fatal("Get() on empty store: ok = true")
+	}
+
+	err = s.Replace("alice", week, []tracker.Worklog{
+		worklog(1, 1, day.Add(10*time.Hour)),
+		worklog(2, 1, day.AddDate(0, 0, 1).Add(10*time.Hour)),
+	}, synced)
+	if err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		createdBy string
+		createdAt timefns.TimeSpan
+		wantOK    bool
+		want      string
+	}{
+		{"Whole period", "alice", week, true, "[1@1 2@1]"},
+		{"Part of a synced day", "alice", timefns.TimeSpan{Start: day.Add(12 * time.Hour), End: day.AddDate(0, 0, 2)}, true, "[2@1]"},
+		{"Day out of synced period", "alice", timefns.TimeSpan{Start: day, End: day.AddDate(0, 0, 8)}, false, "[]"},
+		{"Another author", "bob", week, false, "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worklogs, syncedAt, ok := s.Get(tt.createdBy, tt.createdAt)
+			if ok != tt.wantOK || ids(worklogs) != tt.want {
+				t.Errorf("Get() = %v, %v, want %v, %v", ids(worklogs), ok, tt.want, tt.wantOK)
+			}
+			if ok && !syncedAt.Equal(synced) {
+				t.Errorf("Get() syncedAt = %v, want %v", syncedAt, synced)
+			}
+		})
+	}
+
+	// старая версия не заменяет новую, новая запись того же автора добавляется
+	s.Put(worklog(1, 3, day.Add(10*time.Hour)))
+	s.Put(worklog(1, 2, day.Add(10*time.Hour)))
+	s.Put(worklog(3, 1, synced.Add(time.Minute)))
+	s.Delete("TEST-1", 2)
+	if worklogs, syncedAt, _ := s.Get("alice", week); ids(worklogs) != "[1@3 3@1]" || !syncedAt.Equal(synced) {
+		t.Errorf("Get() after edits = %v, %v", ids(worklogs), syncedAt)
+	}
+
+	reopened, err := store.Open(path)
+	if err != nil {
+		t.Fatalf("Open() existing store error = %v", err)
+	}
+	if worklogs, _, ok := reopened.Get("alice", week); !ok || ids(worklogs) != "[1@3 3@1]" {
+		t.Errorf("Get() after reopen = %v, %v", ids(worklogs), ok)
+	}
+
+	// Replace убирает записи, которых больше нет в Трекере
+	later := synced.Add(time.Hour)
+	s.Replace("alice", week, []tracker.Worklog{worklog(3, 1, synced.Add(time.Minute))}, later)
+	if worklogs, _, _ := s.Get("alice", week); ids(worklogs) != "[3@1]" {
+		t.Errorf("Get() after Replace = %v", ids(worklogs))
+	}
+}
+
+func TestDays(t *testing.T) {
+	start := time.Date(2025, 5, 5, 10, 30, 0, 0, time.Local)
+	got := store.Days(timefns.TimeSpan{Start: start, End: start.AddDate(0, 0, 1)})
+	if want := time.Date(2025, 5, 5, 0, 0, 0, 0, time.Local); !got.Start.Equal(want) {
+		t.Errorf("Days() start = %v, want %v", got.Start, want)
+	}
+	if want := time.Date(2025, 5, 7, 0, 0, 0, 0, time.Local); !got.End.Equal(want) {
+		t.Errorf("Days() end = %v, want %v", got.End, want)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	s, _ := store.Open("")
+	day := time.Date(2025, 5, 5, 0, 0, 0, 0, time.Local)
+	week := timefns.TimeSpan{Start: day, End: day.AddDate(0, 0, 7)}
+	s.Replace("alice", week, []tracker.Worklog{worklog(1, 4, day.Add(time.Hour)), worklog(2, 1, day.Add(2*time.Hour))}, day)
+
+	s.Enqueue(store.Operation{Kind: store.OperationUpdate, IssueKey: "TEST-1", WorklogID: 1, Version: 4, Duration: 2 * time.Hour})
+	// повторное изменение сохраняет исходную версию
+	s.Enqueue(store.Operation{Kind: store.OperationUpdate, IssueKey: "TEST-1", WorklogID: 1, Version: 4, Duration: 3 * time.Hour, Comment: "again"})
+	deleted, _ := s.Enqueue(store.Operation{Kind: store.OperationDelete, IssueKey: "TEST-1", WorklogID: 2})
+	s.Enqueue(store.Operation{Kind: store.OperationCreate, IssueKey: "TEST-2", Start: day, Duration: time.Hour})
+
+	queue := s.Queue()
+	if len(queue) != 3 || queue[0].Duration != 3*time.Hour || queue[0].Version != 4 || queue[1].ID != deleted.ID {
+		t.Errorf("Queue() = %+v", queue)
+	}
+	if worklogs, _, _ := s.Get("alice", week); len(worklogs) != 1 || worklogs[0].Duration != "PT3H" || worklogs[0].Comment != "again" {
+		t.Errorf("Get() with queued changes = %+v", worklogs)
+	}
+
+	s.Fail(queue[0].ID, "conflict")
+	s.Done(queue[1].ID)
+	if queue := s.Queue(); len(queue) != 2 || queue[0].Error != "conflict" {
+		t.Errorf("Queue() after Fail and Done = %+v", queue)
+	}
+}
+
+func TestEnqueueSaveError(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := store.Open(filepath.Join(dir, "cache", "worklogs.json"))
+	day := time.Date(2025, 5, 5, 0, 0, 0, 0, time.Local)
+	week := timefns.TimeSpan{Start: day, End: day.AddDate(0, 0, 7)}
+	s.Replace("alice", week, []tracker.Worklog{worklog(1, 4, day.Add(time.Hour))}, day)
+
+	// каталог кэша заменен файлом: очередь не сохранить
+	if err := os.RemoveAll(filepath.Join(dir, "cache")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cache"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Enqueue(store.Operation{Kind: store.OperationDelete, IssueKey: "TEST-1", WorklogID: 1}); err == nil {
+		t.Fatal("Enqueue() error = nil, want error")
+	}
+	if queue := s.Queue(); len(queue) != 0 {
+		t.Errorf("Queue() after failed Enqueue = %+v", queue)
+	}
+	if worklogs, _, _ := s.Get("alice", week); ids(worklogs) != "[1@4]" {
+		t.Errorf("Get() after failed Enqueue = %s, want [1@4]", ids(worklogs))
+	}
+}
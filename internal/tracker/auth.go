@@ -1,28 +1,122 @@
 package tracker
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"net/http"
 
 	"example.com/tracker/internal/client"
 )
 
+// TokenSource выдает токен для заголовка Authorization
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// источники, которые умеют сбросить токен после ответа 401
+type invalidator interface {
+	Invalidate()
+}
+
+// StaticToken — неизменяемый токен, например из переменной окружения
+type StaticToken string
+
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// AuthScheme — схема заголовка Authorization
+type AuthScheme string
+
+const (
+	// IAM-токен Yandex Cloud
+	AuthSchemeBearer AuthScheme = "Bearer"
+	// OAuth-токен Яндекс ID
+	AuthSchemeOAuth AuthScheme = "OAuth"
+)
+
+// OrgHeader — заголовок с идентификатором организации
+type OrgHeader string
+
+const (
+	// организация Yandex Cloud Organization
+	OrgHeaderCloud OrgHeader = "X-Cloud-Org-ID"
+	// организация Яндекс 360 для бизнеса
+	OrgHeader360 OrgHeader = "X-Org-ID"
+)
+
+type AuthConfig struct {
+	// по умолчанию AuthSchemeBearer
+	Scheme AuthScheme
+	// по умолчанию OrgHeaderCloud
+	OrgHeader OrgHeader
+	OrgID     string
+}
+
 // Интерсептор для добавления токена
 func AuthTokenInterceptor(token, orgId string) client.Interceptor {
+	return TokenSourceInterceptor(StaticToken(token), orgId)
+}
+
+// Интерсептор для добавления токена из TokenSource.
+// Если источник умеет сбрасывать токен, при ответе 401 запрос повторяется один раз с новым токеном.
+func TokenSourceInterceptor(source TokenSource, orgId string) client.Interceptor {
+	return AuthInterceptor(source, AuthConfig{OrgID: orgId})
+}
+
+// Интерсептор для добавления токена с произвольной схемой и заголовком организации
+func AuthInterceptor(source TokenSource, config AuthConfig) client.Interceptor {
+	if config.Scheme == "" {
+		config.Scheme = AuthSchemeBearer
+	}
+	if config.OrgHeader == "" {
+		config.OrgHeader = OrgHeaderCloud
+	}
 	return func(next http.RoundTripper) http.RoundTripper {
-		return &authRoundTripper{next: next, headers: map[string]string{
-			"Authorization":  "Bearer " + token,
-			"X-Cloud-Org-ID": orgId,
+		return &authRoundTripper{next: next, source: source, scheme: config.Scheme, headers: map[string]string{
+			string(config.OrgHeader): config.OrgID,
 		}}
 	}
 }
 
 type authRoundTripper struct {
 	next    http.RoundTripper
+	source  TokenSource
+	scheme  AuthScheme
 	headers map[string]string
 }
 
 func (a *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := a.roundTrip(req, req.Body)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	inv, ok := a.source.(invalidator)
+	if !ok || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return resp, err
+	}
+	var body io.ReadCloser
+	if req.Body != nil && req.Body != http.NoBody {
+		if body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	inv.Invalidate()
+	return a.roundTrip(req, body)
+}
+
+func (a *authRoundTripper) roundTrip(req *http.Request, body io.ReadCloser) (*http.Response, error) {
+	token, err := a.source.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("error getting auth token: %w", err)
+	}
 	reqClone := req.Clone(req.Context())
+	reqClone.Body = body
+	reqClone.Header.Set("Authorization", string(a.scheme)+" "+token)
 	for key, value := range a.headers {
 		reqClone.Header.Set(key, value)
 	}
@@ -0,0 +1,108 @@
+package tracker
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// задача; в записях о затраченном времени заполнены только ссылка, ключ и название,
+// остальные поля приходят из API задач
+type Issue struct {
+	Self       string   `json:"self"`
+	Id         string   `json:"id"`
+	Key        string   `json:"key"`
+	Display    string   `json:"display"`
+	Version    int      `json:"version,omitempty"`
+	Summary    string   `json:"summary,omitempty"`
+	Queue      Ref      `json:"queue,omitzero"`
+	Status     Ref      `json:"status,omitzero"`
+	Type       Ref      `json:"type,omitzero"`
+	Priority   Ref      `json:"priority,omitzero"`
+	Assignee   User     `json:"assignee,omitzero"`
+	Project    Ref      `json:"project,omitzero"`
+	Epic       Ref      `json:"epic,omitzero"`
+	Parent     Ref      `json:"parent,omitzero"`
+	Components []Ref    `json:"components,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// длительности в формате ISO 8601, например P1W или PT5H
+	OriginalEstimation string `json:"originalEstimation,omitempty"`
+	Estimation         string `json:"estimation,omitempty"`
+	Spent              string `json:"spent,omitempty"`
+	// дата в формате 2006-01-02
+	Deadline  string `json:"deadline,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+// Ref — ссылка на связанный объект: очередь, статус, тип, компонент, проект, эпик
+type Ref struct {
+	Self    string `json:"self"`
+	Id      string `json:"id"`
+	Key     string `json:"key,omitempty"`
+	Display string `json:"display"`
+}
+
+// IssueSearch — условия поиска задач. Трекер принимает только одно из них:
+// очередь, список ключей, фильтр по полям или запрос на языке запросов.
+type IssueSearch struct {
+	Queue string   `json:"queue,omitempty"`
+	Keys  []string `json:"keys,omitempty"`
+	// например {"queue": "TREK", "assignee": "empty()"}
+	Filter map[string]any `json:"filter,omitempty"`
+	// например `Queue: TREK Updated: >= "2025-05-01" "Sort by": Updated DESC`
+	Query string `json:"query,omitempty"`
+
+	// сортировка для Filter, например "+status" или "-updatedAt"
+	Order string `json:"order,omitempty"`
+	// поля задач в ответе; пустой список — все поля
+	Fields []string `json:"-"`
+	// постраничная выдача ограничена 10000 задач; для больших выборок
+	// задачи загружаются прокруткой, без сортировки
+	Scroll bool `json:"-"`
+}
+
+const perScroll = 1000
+
+var ErrInvalidSearch = errors.New("issue search needs exactly one of queue, keys, filter or query")
+
+func (s IssueSearch) validate() error {
+	conditions := 0
+	for _, set := range []bool{s.Queue != "", len(s.Keys) > 0, len(s.Filter) > 0, s.Query != ""} {
+		if set {
+			conditions++
+		}
+	}
+	if conditions != 1 {
+		return ErrInvalidSearch
+	}
+	if s.Order != "" && len(s.Filter) == 0 {
+		return errors.New("issue search order works only with filter, use \"Sort by\" in query")
+	}
+	return nil
+}
+
+// SearchIssues ищет задачи и загружает все страницы результата
+func (t *TrackerClient) SearchIssues(search IssueSearch) ([]Issue, error) {
+	if err := search.validate(); err != nil {
+		return nil, err
+	}
+	params := []keyValue{{"perPage", strconv.Itoa(perPage)}}
+	if search.Scroll {
+		params = []keyValue{{"scrollType", "unsorted"}, {"perScroll", strconv.Itoa(perScroll)}}
+	}
+	if len(search.Fields) > 0 {
+		params = append(params, keyValue{"fields", strings.Join(search.Fields, ",")})
+	}
+	return requestData[[]Issue]{
+		client: t,
+		request: request{
+			path:   "issues/_search",
+			method: http.MethodPost,
+			params: params,
+			body:   search,
+		},
+		merge: appendPage[Issue],
+	}.requestNew()
+}
@@ -0,0 +1,125 @@
+package tracker_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"example.com/tracker/internal/tracker"
+	"example.com/tracker/internal/tracker/trackertest"
+)
+
+func TestGetIssueWorklogs(t *testing.T) {
+	server := newServer(t)
+	worklogs, err := server.NewTrackerClient().GetIssueWorklogs("TEST-1")
+	if err != nil {
+		t.Fatalf("GetIssueWorklogs() error = %v", err)
+	}
+	var ids []int
+	for _, w := range worklogs {
+		ids = append(ids, w.ID)
+	}
+	if fmt.Sprint(ids) != "[1 3 4]" {
+		t.Errorf("GetIssueWorklogs() ids = %v, want [1 3 4]", ids)
+	}
+}
+
+func newIssueServer(t *testing.T) *trackertest.Server {
+	t.Helper()
+	server := newServer(t)
+	data, err := os.ReadFile("testdata/issues.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var issues []tracker.Issue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		t.Fatal(err)
+	}
+	server.SeedIssues(issues...)
+	return server
+}
+
+func TestSearchIssues(t *testing.T) {
+	trackerClient := newIssueServer(t).NewTrackerClient()
+
+	tests := []struct {
+		name   string
+		search tracker.IssueSearch
+		want   string
+	}{
+		{"Queue", tracker.IssueSearch{Queue: "TEST"}, "[TEST-1 TEST-2 TEST-3]"},
+		{"Keys", tracker.IssueSearch{Keys: []string{"TEST-2", "OPS-1"}}, "[OPS-1 TEST-2]"},
+		{"Filter", tracker.IssueSearch{Filter: map[string]any{"status": "open"}}, "[OPS-1 TEST-2]"},
+		{"Filter with order", tracker.IssueSearch{Filter: map[string]any{"tags": "reports"}, Order: "-updatedAt"}, "[TEST-1 TEST-2]"},
+		{"Query", tracker.IssueSearch{Query: `Queue: "TEST" Updated: >= "2025-05-01"`}, "[TEST-1 TEST-2 TEST-3]"},
+		{"Fields", tracker.IssueSearch{Queue: "OPS", Fields: []string{"key", "summary"}}, "[OPS-1]"},
+		{"Scroll", tracker.IssueSearch{Queue: "TEST", Scroll: true}, "[TEST-1 TEST-2 TEST-3]"},
+		{"Nothing found", tracker.IssueSearch{Queue: "NONE"}, "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := trackerClient.SearchIssues(tt.search)
+			if err != nil {
+				t.Fatalf("SearchIssues() error = %v", err)
+			}
+			keys := []string{}
+			for _, issue := range issues {
+				keys = append(keys, issue.Key)
+			}
+			if fmt.Sprint(keys) != tt.want {
+				t.Errorf("SearchIssues() = %v, want %v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchIssuesFields(t *testing.T) {
+	issues, err := newIssueServer(t).NewTrackerClient().SearchIssues(tracker.IssueSearch{Keys: []string{"TEST-1"}})
+	if err != nil || len(issues) != 1 {
+		t.Fatalf("SearchIssues() = %v, %v", issues, err)
+	}
+	issue := issues[0]
+	got := []string{issue.Queue.Key, issue.Status.Key, issue.Type.Key, issue.Assignee.Display, issue.Epic.Key,
+		issue.Components[0].Display, fmt.Sprint(issue.Tags), issue.OriginalEstimation, issue.Estimation, issue.Spent, issue.Deadline}
+	want := []string{"TEST", "inProgress", "task", "Алиса", "TEST-10", "Backend", "[billing reports]", "P1D", "PT5H", "PT8H", "2025-06-01"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("SearchIssues() issue fields = %v, want %v", got, want)
+	}
+}
+
+func TestSearchIssuesPaging(t *testing.T) {
+	server := trackertest.NewServer()
+	defer server.Close()
+	for i := range 250 {
+		server.SeedIssues(tracker.Issue{Key: fmt.Sprintf("BIG-%03d", i)})
+	}
+	trackerClient := server.NewTrackerClient()
+
+	for _, scroll := range []bool{false, true} {
+		issues, err := trackerClient.SearchIssues(tracker.IssueSearch{Queue: "BIG", Scroll: scroll})
+		if err != nil {
+			t.Fatalf("SearchIssues(scroll: %v) error = %v", scroll, err)
+		}
+		if len(issues) != 250 {
+			t.Errorf("SearchIssues(scroll: %v) returned %d issues, want 250", scroll, len(issues))
+		}
+	}
+	last := server.Requests()[len(server.Requests())-1]
+	if last.Query.Get("scrollId") == "" || last.Header.Get("X-Scroll-Token") == "" {
+		t.Errorf("scroll request = %+v, want scrollId and X-Scroll-Token", last)
+	}
+}
+
+func TestSearchIssuesValidation(t *testing.T) {
+	trackerClient := newServer(t).NewTrackerClient()
+	for _, search := range []tracker.IssueSearch{
+		{},
+		{Queue: "TEST", Query: "Queue: TEST"},
+		{Queue: "TEST", Order: "+key"},
+	} {
+		if _, err := trackerClient.SearchIssues(search); err == nil {
+			t.Errorf("SearchIssues(%+v) error = nil", search)
+		}
+	}
+}
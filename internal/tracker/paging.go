@@ -0,0 +1,64 @@
+package tracker
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+const (
+	perPage  = 100
+	maxPages = 1000
+)
+
+type pageRequest struct {
+	url     string
+	params  []keyValue
+	headers map[string]string
+}
+
+var linkNextRe = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// nextPage определяет, как запросить следующую страницу ответа.
+// Трекер сообщает о ней заголовком Link (rel="next"), скролл-заголовками
+// X-Scroll-Id/X-Scroll-Token или общим числом страниц в X-Total-Pages.
+func nextPage(header http.Header, params []keyValue, page, received int) (pageRequest, bool) {
+	for _, link := range header.Values("Link") {
+		if m := linkNextRe.FindStringSubmatch(link); m != nil {
+			return pageRequest{url: m[1]}, true
+		}
+	}
+
+	if scrollId := header.Get("X-Scroll-Id"); scrollId != "" {
+		if received == 0 {
+			return pageRequest{}, false
+		}
+		next := pageRequest{params: append(withoutParams(params, "page", "scrollId"), keyValue{"scrollId", scrollId})}
+		if token := header.Get("X-Scroll-Token"); token != "" {
+			next.headers = map[string]string{"X-Scroll-Token": token}
+		}
+		return next, true
+	}
+
+	if totalPages, err := strconv.Atoi(header.Get("X-Total-Pages")); err == nil && page < totalPages {
+		return pageRequest{params: append(withoutParams(params, "page"), keyValue{"page", strconv.Itoa(page + 1)})}, true
+	}
+	return pageRequest{}, false
+}
+
+func withoutParams(params []keyValue, keys ...string) []keyValue {
+	result := make([]keyValue, 0, len(params)+1)
+	for _, param := range params {
+		skip := false
+		for _, key := range keys {
+			if param.key == key {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			result = append(result, param)
+		}
+	}
+	return result
+}
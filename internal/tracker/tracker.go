@@ -2,29 +2,50 @@ package tracker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"example.com/tracker/internal/client"
 )
 
-const baseUrl = "https://api.tracker.yandex.net/v3/"
+const (
+	DefaultAPIURL     = "https://api.tracker.yandex.net"
+	DefaultAPIVersion = "v3"
+)
+
+// ErrVersionConflict возвращается, если запись изменили после того, как была прочитана ее версия
+var ErrVersionConflict = errors.New("version conflict")
 
 type Config struct {
 	Ctx     context.Context
 	Timeout time.Duration
 	Client  *client.Client
+	// адрес веб-интерфейса Трекера, для ссылок на задачи
 	HostURL string
+	// адрес API, по умолчанию DefaultAPIURL
+	APIURL string
+	// версия API: v2 или v3, по умолчанию DefaultAPIVersion
+	APIVersion string
 }
 
 type TrackerClient struct {
 	Config
+	baseUrl string
 }
 
 func NewTrackerClient(config Config) *TrackerClient {
+	if config.APIURL == "" {
+		config.APIURL = DefaultAPIURL
+	}
+	if config.APIVersion == "" {
+		config.APIVersion = DefaultAPIVersion
+	}
 	return &TrackerClient{
-		Config: config,
+		Config:  config,
+		baseUrl: strings.TrimSuffix(config.APIURL, "/") + "/" + config.APIVersion + "/",
 	}
 }
 
@@ -35,43 +56,85 @@ type request struct {
 	path, method string
 	headers      map[string]string
 	params       []keyValue
+	body         interface{}
 }
 type response[T any] struct {
 	statusCode int
+	header     http.Header
 	body       T
 }
 type requestData[T any] struct {
 	client   *TrackerClient
 	request  request
 	response response[T]
+	// merge склеивает страницы ответа и возвращает число полученных записей;
+	// если не задан, запрашивается только одна страница
+	merge func(acc, page T) (T, int)
 }
 
 func (r requestData[T]) requestNew() (T, error) {
-	url := baseUrl + r.request.path
+	var result T
+	url := r.client.baseUrl + r.request.path
+	next := pageRequest{url: url, params: r.request.params}
 
-	ctx, cancel := context.WithTimeout(r.client.Config.Ctx, r.client.Config.Timeout)
-	defer cancel()
+	for page := 1; ; page++ {
+		resp, err := r.requestPage(next)
+		if err != nil {
+			return result, err
+		}
+		if r.merge == nil {
+			return resp.body, nil
+		}
+		var received int
+		result, received = r.merge(result, resp.body)
 
-	if req, err := r.client.Config.Client.NewRequest(ctx, r.request.method, url, nil); err != nil {
-		return r.response.body, fmt.Errorf("creating request: %w", err)
-	} else {
-		query := req.URL.Query()
-		for _, param := range r.request.params {
-			query.Add(param.key, param.value)
+		if page >= maxPages {
+			return result, fmt.Errorf("too many pages: stopped after %d", page)
 		}
-		req.URL.RawQuery = query.Encode()
-		for key, value := range r.request.headers {
-			req.Header.Add(key, value)
+		var ok bool
+		if next, ok = nextPage(resp.header, r.request.params, page, received); !ok {
+			return result, nil
 		}
-		req.Header.Add("Accept", "application/json")
+		if next.url == "" {
+			next.url = url
+		}
+	}
+}
 
-		status, err := r.client.Config.Client.Do(req, &r.response.body)
+func (r requestData[T]) requestPage(p pageRequest) (response[T], error) {
+	var resp response[T]
 
-		if err != nil {
-			return r.response.body, fmt.Errorf("executing request: %w", err)
-		} else if status != http.StatusOK {
-			return r.response.body, fmt.Errorf("received status code %d", status)
-		}
-		return r.response.body, nil
+	ctx, cancel := context.WithTimeout(r.client.Config.Ctx, r.client.Config.Timeout)
+	defer cancel()
+
+	req, err := r.client.Config.Client.NewJSONRequest(ctx, r.request.method, p.url, r.request.body)
+	if err != nil {
+		return resp, fmt.Errorf("creating request: %w", err)
+	}
+	query := req.URL.Query()
+	for _, param := range p.params {
+		query.Add(param.key, param.value)
 	}
+	req.URL.RawQuery = query.Encode()
+	for key, value := range r.request.headers {
+		req.Header.Add(key, value)
+	}
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Add("Accept", "application/json")
+
+	resp.statusCode, resp.header, err = r.client.Config.Client.DoWithHeader(req, &resp.body)
+	if resp.statusCode == http.StatusConflict {
+		return resp, fmt.Errorf("executing request: %w: %w", ErrVersionConflict, err)
+	} else if err != nil {
+		return resp, fmt.Errorf("executing request: %w", err)
+	} else if !client.IsSuccess(resp.statusCode) {
+		return resp, fmt.Errorf("received status code %d", resp.statusCode)
+	}
+	return resp, nil
+}
+
+func appendPage[T any](acc, page []T) ([]T, int) {
+	return append(acc, page...), len(page)
 }
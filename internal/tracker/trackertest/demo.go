@@ -0,0 +1,72 @@
+package trackertest
+
+import (
+	"context"
+	"time"
+
+	"example.com/tracker/internal/client"
+	"example.com/tracker/internal/tracker"
+	"github.com/AianaM/timefns"
+)
+
+// NewTrackerClient возвращает клиент, настроенный на этот сервер
+func (s *Server) NewTrackerClient() *tracker.TrackerClient {
+	return tracker.NewTrackerClient(tracker.Config{
+		Ctx:     context.Background(),
+		Timeout: 5 * time.Second,
+		Client: client.New([]client.Interceptor{
+			tracker.AuthTokenInterceptor(s.Token, s.OrgID),
+		}),
+		APIURL: s.APIURL(),
+	})
+}
+
+var demoIssues = []tracker.Issue{
+	{Key: "DEMO-1", Display: "Настроить отчеты по затратам времени"},
+	{Key: "DEMO-2", Display: "Ревью и поддержка"},
+	{Key: "DEMO-3", Display: "Встречи"},
+	{Key: "OPS-7", Display: "Обновить сертификаты"},
+}
+
+type demoEntry struct {
+	issue    tracker.Issue
+	hour     int
+	duration string
+	comment  string
+}
+
+// SeedDemo заполняет сервер записями пользователя login за последние 45 дней:
+// по несколько записей в каждый рабочий день
+func (s *Server) SeedDemo(login string, now time.Time) {
+	author := tracker.User{Id: login, Display: login}
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var worklogs []tracker.Worklog
+	for i := 45; i >= 0; i-- {
+		date := day.AddDate(0, 0, -i)
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			continue
+		}
+		entries := []demoEntry{
+			{demoIssues[i%2], 10, "PT4H", "Разработка"},
+			{demoIssues[2], 14, "PT1H", "Планирование"},
+			{demoIssues[(i+1)%2], 15, "PT2H30M", ""},
+		}
+		if i%5 == 0 {
+			entries = append(entries, demoEntry{demoIssues[3], 18, "PT45M", "Дежурство"})
+		}
+		for _, e := range entries {
+			start := date.Add(time.Duration(e.hour) * time.Hour).Format(timefns.ISO8601n)
+			worklogs = append(worklogs, tracker.Worklog{
+				Issue:     e.issue,
+				Comment:   e.comment,
+				CreatedBy: author,
+				UpdatedBy: author,
+				CreatedAt: start,
+				UpdatedAt: start,
+				Start:     start,
+				Duration:  e.duration,
+			})
+		}
+	}
+	s.Seed(worklogs...)
+}
@@ -0,0 +1,524 @@
+// Package trackertest — фейковый Трекер на httptest для тестов и демо-режима без доступа к API.
+// Поддерживает список записей о затраченном времени с фильтрами и страницами,
+// записи задачи, поиск задач со страницами и прокруткой, добавление, изменение и удаление записей,
+// проверку авторизации и внедрение ошибок.
+package trackertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/tracker/internal/tracker"
+	"github.com/AianaM/durationiso8601"
+	"github.com/AianaM/timefns"
+)
+
+const (
+	Token = "fake-token"
+	OrgID = "fake-org"
+
+	defaultPerPage = 50
+)
+
+// Failure — ошибка, которую сервер вернет вместо обычного ответа
+type Failure struct {
+	// метод и подстрока пути запроса; пустые значения подходят к любому запросу
+	Method string
+	Path   string
+	Status int
+	Body   string
+	// сколько раз вернуть ошибку; 0 — всегда
+	Times int
+}
+
+// Request — запись о запросе, пришедшем на сервер
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+}
+
+type Server struct {
+	*httptest.Server
+	// если задан, запросы без "Bearer <Token>" или "OAuth <Token>" получают 401
+	Token string
+	// если задан, запросы без X-Cloud-Org-ID или X-Org-ID с этим значением получают 401
+	OrgID string
+	// логин автора записей, добавленных через API; пустой — fake-user
+	User string
+
+	mu       sync.Mutex
+	worklogs []tracker.Worklog
+	issues   map[string]tracker.Issue
+	nextID   int
+	failures []*Failure
+	requests []Request
+}
+
+// NewServer запускает фейковый Трекер, который требует Token и OrgID
+func NewServer() *Server {
+	s := &Server{Token: Token, OrgID: OrgID, nextID: 1}
+	s.Server = httptest.NewServer(s.Handler())
+	return s
+}
+
+// APIURL — адрес для tracker.Config.APIURL
+func (s *Server) APIURL() string {
+	return s.URL
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{version}/worklog", s.listWorklogs)
+	mux.HandleFunc("GET /{version}/worklog/{$}", s.listWorklogs)
+	mux.HandleFunc("POST /{version}/issues/_search", s.searchIssues)
+	mux.HandleFunc("GET /{version}/issues/{key}/worklog", s.listIssueWorklogs)
+	mux.HandleFunc("POST /{version}/issues/{key}/worklog", s.createWorklog)
+	mux.HandleFunc("PATCH /{version}/issues/{key}/worklog/{id}", s.updateWorklog)
+	mux.HandleFunc("DELETE /{version}/issues/{key}/worklog/{id}", s.deleteWorklog)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.record(r)
+		if failure := s.takeFailure(r); failure != nil {
+			writeError(w, failure.Status, failure.Body)
+			return
+		}
+		if !s.authorized(r) {
+			writeError(w, http.StatusUnauthorized, "Not authorized")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// Seed добавляет записи; записям без ID назначается очередной номер
+func (s *Server) Seed(worklogs ...tracker.Worklog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range worklogs {
+		if w.ID == 0 {
+			w.ID = s.nextID
+		}
+		s.nextID = max(s.nextID, w.ID+1)
+		if w.Version == 0 {
+			w.Version = 1
+		}
+		s.worklogs = append(s.worklogs, w)
+	}
+}
+
+// SeedIssues добавляет задачи для поиска; задачи из записей добавлять не нужно
+func (s *Server) SeedIssues(issues ...tracker.Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.issues == nil {
+		s.issues = map[string]tracker.Issue{}
+	}
+	for _, issue := range issues {
+		s.issues[issue.Key] = issue
+	}
+}
+
+// LoadFixtures добавляет записи из JSON-массива в формате ответа GET /v3/worklog
+func (s *Server) LoadFixtures(r io.Reader) error {
+	var worklogs []tracker.Worklog
+	if err := json.NewDecoder(r).Decode(&worklogs); err != nil {
+		return fmt.Errorf("error decoding fixtures: %w", err)
+	}
+	s.Seed(worklogs...)
+	return nil
+}
+
+// LoadFixturesFile добавляет записи из JSON-файла
+func (s *Server) LoadFixturesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening fixtures: %w", err)
+	}
+	defer f.Close()
+	return s.LoadFixtures(f)
+}
+
+// Worklogs возвращает копию текущих записей
+func (s *Server) Worklogs() []tracker.Worklog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.worklogs)
+}
+
+func (s *Server) InjectFailure(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// ClearFailures убирает все внедренные ошибки
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// Requests возвращает все запросы, пришедшие на сервер
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+func (s *Server) record(r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, Request{r.Method, r.URL.Path, r.URL.Query(), r.Header.Clone()})
+}
+
+func (s *Server) takeFailure(r *http.Request) *Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.failures {
+		if (f.Method != "" && f.Method != r.Method) || !strings.Contains(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				s.failures = slices.Delete(s.failures, i, i+1)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.Token != "" {
+		auth := r.Header.Get("Authorization")
+		if auth != "Bearer "+s.Token && auth != "OAuth "+s.Token {
+			return false
+		}
+	}
+	if s.OrgID != "" && r.Header.Get("X-Cloud-Org-ID") != s.OrgID && r.Header.Get("X-Org-ID") != s.OrgID {
+		return false
+	}
+	return true
+}
+
+func (s *Server) listWorklogs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var from, to time.Time
+	for _, value := range query["createdAt"] {
+		var err error
+		if v, ok := strings.CutPrefix(value, "from:"); ok {
+			from, err = time.Parse(time.RFC3339Nano, v)
+		} else if v, ok := strings.CutPrefix(value, "to:"); ok {
+			to, err = time.Parse(time.RFC3339Nano, v)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid createdAt: "+value)
+			return
+		}
+	}
+	createdBy := query.Get("createdBy")
+
+	s.mu.Lock()
+	matched := []tracker.Worklog{}
+	for _, wl := range s.worklogs {
+		if createdBy != "" && wl.CreatedBy.Id != createdBy && wl.CreatedBy.Display != createdBy {
+			continue
+		}
+		createdAt, err := timefns.Parse(wl.CreatedAt)
+		if err != nil || (!from.IsZero() && createdAt.Before(from)) || (!to.IsZero() && createdAt.After(to)) {
+			continue
+		}
+		matched = append(matched, wl)
+	}
+	s.mu.Unlock()
+
+	writePage(w, r, matched)
+}
+
+func (s *Server) listIssueWorklogs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	matched := []tracker.Worklog{}
+	for _, wl := range s.worklogs {
+		if wl.Issue.Key == r.PathValue("key") {
+			matched = append(matched, wl)
+		}
+	}
+	s.mu.Unlock()
+	writePage(w, r, matched)
+}
+
+var queueQueryRe = regexp.MustCompile(`(?i)queue:\s*"?([\w-]+)"?`)
+
+// searchIssues ищет среди задач, добавленных SeedIssues, и задач из записей.
+// Поддерживаются очередь, ключи, фильтр по полям-строкам, запрос вида "Queue: KEY ...",
+// сортировка по полю-строке и прокрутка (scrollType).
+func (s *Server) searchIssues(w http.ResponseWriter, r *http.Request) {
+	var body tracker.IssueSearch
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+	filter := maps.Clone(body.Filter)
+	if filter == nil {
+		filter = map[string]any{}
+	}
+	if body.Queue != "" {
+		filter["queue"] = body.Queue
+	}
+	if m := queueQueryRe.FindStringSubmatch(body.Query); m != nil {
+		filter["queue"] = m[1]
+	}
+
+	matched := []tracker.Issue{}
+	for _, issue := range s.allIssues() {
+		if len(body.Keys) > 0 && !slices.Contains(body.Keys, issue.Key) {
+			continue
+		}
+		ok := true
+		for field, value := range filter {
+			ok = ok && slices.Contains(issueField(issue, field), fmt.Sprint(value))
+		}
+		if ok {
+			matched = append(matched, issue)
+		}
+	}
+	if field, desc := strings.TrimLeft(body.Order, "+-"), strings.HasPrefix(body.Order, "-"); field != "" {
+		slices.SortStableFunc(matched, func(a, b tracker.Issue) int {
+			c := strings.Compare(strings.Join(issueField(a, field), ","), strings.Join(issueField(b, field), ","))
+			if desc {
+				return -c
+			}
+			return c
+		})
+	}
+
+	if r.URL.Query().Get("scrollType") != "" {
+		writeScroll(w, r, matched)
+		return
+	}
+	writePage(w, r, matched)
+}
+
+// issueField возвращает значения поля задачи для фильтра и сортировки: ключи и названия
+func issueField(issue tracker.Issue, field string) []string {
+	ref := func(r tracker.Ref) []string { return []string{r.Key, r.Id, r.Display} }
+	switch field {
+	case "key":
+		return []string{issue.Key}
+	case "summary":
+		return []string{issue.Summary}
+	case "queue":
+		return ref(issue.Queue)
+	case "status":
+		return ref(issue.Status)
+	case "type":
+		return ref(issue.Type)
+	case "assignee":
+		return []string{issue.Assignee.Id, issue.Assignee.Display}
+	case "tags":
+		return issue.Tags
+	case "components":
+		var values []string
+		for _, c := range issue.Components {
+			values = append(values, ref(c)...)
+		}
+		return values
+	case "updatedAt":
+		return []string{issue.UpdatedAt}
+	default:
+		return nil
+	}
+}
+
+// allIssues возвращает задачи по ключу: добавленные SeedIssues и упомянутые в записях.
+// Очередь, если не задана, берется из ключа.
+func (s *Server) allIssues() []tracker.Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issues := map[string]tracker.Issue{}
+	for _, wl := range s.worklogs {
+		issues[wl.Issue.Key] = wl.Issue
+	}
+	for key, issue := range s.issues {
+		issues[key] = issue
+	}
+	for key, issue := range issues {
+		if issue.Queue.Key == "" {
+			issue.Queue.Key, _, _ = strings.Cut(key, "-")
+			issues[key] = issue
+		}
+	}
+	return slices.SortedFunc(maps.Values(issues), func(a, b tracker.Issue) int { return strings.Compare(a.Key, b.Key) })
+}
+
+// writeScroll отвечает порцией прокрутки; X-Scroll-Id — смещение следующей порции
+func writeScroll[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	query := r.URL.Query()
+	perScroll := intParam(query, "perScroll", defaultPerPage)
+	start, _ := strconv.Atoi(query.Get("scrollId"))
+	start = min(start, len(items))
+	end := min(start+perScroll, len(items))
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(items)))
+	w.Header().Set("X-Scroll-Id", strconv.Itoa(end))
+	w.Header().Set("X-Scroll-Token", "token-"+strconv.Itoa(end))
+	writeJSON(w, http.StatusOK, items[start:end])
+}
+
+// writePage отвечает страницей списка с заголовками X-Total-Pages и Link, как Трекер
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	query := r.URL.Query()
+	perPage := intParam(query, "perPage", defaultPerPage)
+	page := intParam(query, "page", 1)
+	totalPages := max((len(items)+perPage-1)/perPage, 1)
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(items)))
+	w.Header().Set("X-Total-Pages", strconv.Itoa(totalPages))
+	if page < totalPages {
+		next := *r.URL
+		next.Scheme, next.Host = "http", r.Host
+		q := next.Query()
+		q.Set("page", strconv.Itoa(page+1))
+		next.RawQuery = q.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+	}
+	writeJSON(w, http.StatusOK, items[start:end])
+}
+
+type worklogBody struct {
+	Start    string `json:"start"`
+	Duration string `json:"duration"`
+	Comment  string `json:"comment"`
+}
+
+func (s *Server) createWorklog(w http.ResponseWriter, r *http.Request) {
+	var body worklogBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+	start, err := timefns.Parse(body.Start)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid start: "+body.Start)
+		return
+	}
+	if _, err := durationiso8601.ParseDuration(start, body.Duration); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid duration: "+body.Duration)
+		return
+	}
+
+	now := time.Now().Format(timefns.ISO8601n)
+	login := s.User
+	if login == "" {
+		login = "fake-user"
+	}
+	author := tracker.User{Id: login, Display: login}
+	s.mu.Lock()
+	wl := tracker.Worklog{
+		ID:        s.nextID,
+		Version:   1,
+		Issue:     tracker.Issue{Key: r.PathValue("key"), Display: r.PathValue("key")},
+		Comment:   body.Comment,
+		CreatedBy: author,
+		UpdatedBy: author,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Start:     body.Start,
+		Duration:  body.Duration,
+	}
+	s.nextID++
+	s.worklogs = append(s.worklogs, wl)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, wl)
+}
+
+func (s *Server) updateWorklog(w http.ResponseWriter, r *http.Request) {
+	var body worklogBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.find(r.PathValue("key"), r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Worklog not found")
+		return
+	}
+	wl := &s.worklogs[i]
+	if version := r.URL.Query().Get("version"); version != "" && version != strconv.Itoa(wl.Version) {
+		writeError(w, http.StatusConflict, "Worklog version conflict")
+		return
+	}
+	if body.Start != "" {
+		wl.Start = body.Start
+	}
+	if body.Duration != "" {
+		wl.Duration = body.Duration
+	}
+	wl.Comment = body.Comment
+	wl.Version++
+	wl.UpdatedAt = time.Now().Format(timefns.ISO8601n)
+	writeJSON(w, http.StatusOK, *wl)
+}
+
+func (s *Server) deleteWorklog(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.find(r.PathValue("key"), r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Worklog not found")
+		return
+	}
+	s.worklogs = slices.Delete(s.worklogs, i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) find(issueKey, id string) int {
+	return slices.IndexFunc(s.worklogs, func(wl tracker.Worklog) bool {
+		return wl.Issue.Key == issueKey && strconv.Itoa(wl.ID) == id
+	})
+}
+
+func intParam(query url.Values, key string, defaultValue int) int {
+	if value, err := strconv.Atoi(query.Get(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError отвечает ошибкой в формате Трекера
+func writeError(w http.ResponseWriter, status int, message string) {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	writeJSON(w, status, map[string]any{
+		"errors":        map[string]string{},
+		"errorMessages": []string{message},
+		"statusCode":    status,
+	})
+}
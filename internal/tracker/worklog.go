@@ -1,7 +1,11 @@
 package tracker
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AianaM/timefns"
@@ -22,13 +26,6 @@ type Worklog struct {
 	Duration  string `json:"duration"`
 }
 
-type Issue struct {
-	Self    string `json:"self"`
-	Id      string `json:"id"`
-	Key     string `json:"key"`
-	Display string `json:"display"`
-}
-
 type User struct {
 	Self    string `json:"self"`
 	Id      string `json:"id"`
@@ -45,7 +42,154 @@ func (t *TrackerClient) GetWorklog(createdBy string, createdAt timefns.TimeSpan)
 				{"createdBy", createdBy},
 				{"createdAt", "from:" + createdAt.Start.Format(time.RFC3339Nano)},
 				{"createdAt", "to:" + createdAt.End.Format(time.RFC3339Nano)},
+				{"perPage", strconv.Itoa(perPage)},
 			},
 		},
+		merge: appendPage[Worklog],
 	}.requestNew()
 }
+
+// Трекер ищет записи только по дате создания. Время обычно вносят в день
+// работы или позже, иногда заранее, поэтому записи по дате начала работы
+// ищутся в расширенном окне создания и отбираются по start: от startWindowLead
+// до начала периода до startWindowLag после его конца. Запись, внесенная
+// раньше или позже окна, не найдется.
+const (
+	startWindowLead = 7 * 24 * time.Hour
+	startWindowLag  = 31 * 24 * time.Hour
+)
+
+// StartWindowNote — окно поиска записей по началу работы для подсказок
+// интерфейса и справки командной строки
+func StartWindowNote() string {
+	day := 24 * time.Hour
+	return fmt.Sprintf("finds worklogs logged from %d days before to %d days after the work", startWindowLead/day, startWindowLag/day)
+}
+
+// StartWindow возвращает период создания, в котором ищутся записи
+// с началом работы в период start; окно не заходит в будущее
+func StartWindow(start timefns.TimeSpan) timefns.TimeSpan {
+	window := timefns.TimeSpan{Start: start.Start.Add(-startWindowLead), End: start.End.Add(startWindowLag)}
+	if now := time.Now(); window.End.After(now) {
+		window.End = now
+	}
+	if window.End.Before(window.Start) {
+		window.End = window.Start
+	}
+	return window
+}
+
+// StartedIn оставляет записи, работа по которым началась в [start.Start, start.End)
+func StartedIn(worklogs []Worklog, start timefns.TimeSpan) []Worklog {
+	started := []Worklog{}
+	for _, w := range worklogs {
+		t, err := timefns.Parse(w.Start)
+		if err == nil && !t.Before(start.Start) && t.Before(start.End) {
+			started = append(started, w)
+		}
+	}
+	return started
+}
+
+// GetWorklogByStart возвращает записи автора, работа по которым началась в период start
+func (t *TrackerClient) GetWorklogByStart(createdBy string, start timefns.TimeSpan) ([]Worklog, error) {
+	worklogs, err := t.GetWorklog(createdBy, StartWindow(start))
+	if err != nil {
+		return nil, err
+	}
+	return StartedIn(worklogs, start), nil
+}
+
+// GetIssueWorklogs возвращает все записи о затраченном времени в задаче
+func (t *TrackerClient) GetIssueWorklogs(issueKey string) ([]Worklog, error) {
+	return requestData[[]Worklog]{
+		client: t,
+		request: request{
+			path:   issueWorklogPath(issueKey),
+			method: http.MethodGet,
+			params: []keyValue{{"perPage", strconv.Itoa(perPage)}},
+		},
+		merge: appendPage[Worklog],
+	}.requestNew()
+}
+
+type worklogBody struct {
+	Start    string `json:"start,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Comment  string `json:"comment"`
+}
+
+func newWorklogBody(start time.Time, duration time.Duration, comment string) worklogBody {
+	body := worklogBody{Comment: comment}
+	if !start.IsZero() {
+		body.Start = start.Format(timefns.ISO8601n)
+	}
+	if duration > 0 {
+		body.Duration = FormatDuration(duration)
+	}
+	return body
+}
+
+func issueWorklogPath(issueKey string) string {
+	return "issues/" + url.PathEscape(issueKey) + "/worklog"
+}
+
+// CreateWorklog добавляет запись о затраченном времени в задачу
+func (t *TrackerClient) CreateWorklog(issueKey string, start time.Time, duration time.Duration, comment string) (Worklog, error) {
+	return requestData[Worklog]{
+		client: t,
+		request: request{
+			path:   issueWorklogPath(issueKey),
+			method: http.MethodPost,
+			body:   newWorklogBody(start, duration, comment),
+		},
+	}.requestNew()
+}
+
+// UpdateWorklog изменяет запись о затраченном времени.
+// version — версия записи, на основе которой сделаны изменения;
+// если запись успели изменить, вернется ErrVersionConflict.
+// Нулевые start и duration не изменяются.
+func (t *TrackerClient) UpdateWorklog(issueKey string, id, version int, start time.Time, duration time.Duration, comment string) (Worklog, error) {
+	return requestData[Worklog]{
+		client: t,
+		request: request{
+			path:   issueWorklogPath(issueKey) + "/" + strconv.Itoa(id),
+			method: http.MethodPatch,
+			params: []keyValue{{"version", strconv.Itoa(version)}},
+			body:   newWorklogBody(start, duration, comment),
+		},
+	}.requestNew()
+}
+
+// DeleteWorklog удаляет запись о затраченном времени
+func (t *TrackerClient) DeleteWorklog(issueKey string, id int) error {
+	_, err := requestData[struct{}]{
+		client: t,
+		request: request{
+			path:   issueWorklogPath(issueKey) + "/" + strconv.Itoa(id),
+			method: http.MethodDelete,
+		},
+	}.requestNew()
+	return err
+}
+
+// FormatDuration форматирует длительность в ISO 8601, например PT1H30M или PT1M30S;
+// доли секунды отбрасываются
+func FormatDuration(d time.Duration) string {
+	var b strings.Builder
+	b.WriteString("PT")
+	if h := int(d / time.Hour); h > 0 {
+		b.WriteString(strconv.Itoa(h) + "H")
+	}
+	if m := int((d % time.Hour) / time.Minute); m > 0 {
+		b.WriteString(strconv.Itoa(m) + "M")
+	}
+	if s := int((d % time.Minute) / time.Second); s > 0 {
+		b.WriteString(strconv.Itoa(s) + "S")
+	}
+	if b.Len() == len("PT") {
+		b.WriteString("0M")
+	}
+	return b.String()
+}
@@ -0,0 +1,217 @@
+package tracker_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"example.com/tracker/internal/client"
+	"example.com/tracker/internal/tracker"
+	"example.com/tracker/internal/tracker/trackertest"
+	"github.com/AianaM/timefns"
+)
+
+func newServer(t *testing.T) *trackertest.Server {
+	t.Helper()
+	server := trackertest.NewServer()
+	t.Cleanup(server.Close)
+	if err := server.LoadFixturesFile("testdata/worklogs.json"); err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestGetWorklog(t *testing.T) {
+	server := newServer(t)
+	trackerClient := server.NewTrackerClient()
+
+	tests := []struct {
+		name      string
+		createdBy string
+		createdAt timefns.TimeSpan
+		wantIDs   []int
+	}{
+		{"Filters by author and creation date", "alice", timefns.TimeSpan{Start: date(2025, 5, 1), End: date(2025, 6, 1)}, []int{1, 2, 3}},
+		{"Narrow creation window", "alice", timefns.TimeSpan{Start: date(2025, 5, 6), End: date(2025, 5, 7)}, []int{2}},
+		{"Another author", "bob", timefns.TimeSpan{Start: date(2025, 5, 1), End: date(2025, 6, 1)}, []int{5}},
+		{"No worklogs", "carol", timefns.TimeSpan{Start: date(2025, 5, 1), End: date(2025, 6, 1)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worklogs, err := trackerClient.GetWorklog(tt.createdBy, tt.createdAt)
+			if err != nil {
+				t.Fatalf("GetWorklog() error = %v", err)
+			}
+			var ids []int
+			for _, w := range worklogs {
+				ids = append(ids, w.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("GetWorklog() ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestGetWorklogByStart(t *testing.T) {
+	trackerClient := newServer(t).NewTrackerClient()
+
+	// запись 3 внесена 7 мая за работу 6 мая
+	worklogs, err := trackerClient.GetWorklogByStart("alice", timefns.TimeSpan{Start: date(2025, 5, 6), End: date(2025, 5, 7)})
+	if err != nil {
+		t.Fatalf("GetWorklogByStart() error = %v", err)
+	}
+	var ids []int
+	for _, w := range worklogs {
+		ids = append(ids, w.ID)
+	}
+	if fmt.Sprint(ids) != "[2 3]" {
+		t.Errorf("GetWorklogByStart() ids = %v, want [2 3]", ids)
+	}
+
+	window := tracker.StartWindow(timefns.TimeSpan{Start: date(2025, 5, 6), End: date(2025, 5, 7)})
+	if !window.Start.Before(date(2025, 5, 6)) || !window.End.After(date(2025, 5, 7)) {
+		t.Errorf("StartWindow() = %v - %v, want a wider window", window.Start, window.End)
+	}
+	if future := tracker.StartWindow(timefns.Today()); future.End.After(time.Now()) {
+		t.Errorf("StartWindow() end = %v, want not after now", future.End)
+	}
+}
+
+func TestGetWorklogPaging(t *testing.T) {
+	server := trackertest.NewServer()
+	defer server.Close()
+
+	const total = 250
+	start := date(2025, 5, 1)
+	for i := range total {
+		created := start.Add(time.Duration(i) * time.Minute).Format(timefns.ISO8601n)
+		server.Seed(tracker.Worklog{
+			Issue:     tracker.Issue{Key: "TEST-1"},
+			CreatedBy: tracker.User{Id: "alice"},
+			CreatedAt: created,
+			Start:     created,
+			Duration:  "PT1M",
+		})
+	}
+
+	worklogs, err := server.NewTrackerClient().GetWorklog("alice", timefns.TimeSpan{Start: start, End: start.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("GetWorklog() error = %v", err)
+	}
+	if len(worklogs) != total {
+		t.Errorf("GetWorklog() returned %d worklogs, want %d", len(worklogs), total)
+	}
+	if requests := len(server.Requests()); requests != 3 {
+		t.Errorf("GetWorklog() made %d requests, want 3", requests)
+	}
+}
+
+func TestGetWorklogErrors(t *testing.T) {
+	createdAt := timefns.TimeSpan{Start: date(2025, 5, 1), End: date(2025, 6, 1)}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		server := newServer(t)
+		trackerClient := server.NewTrackerClient()
+		server.Token = "another-token"
+		_, err := trackerClient.GetWorklog("alice", createdAt)
+
+		var apiErr *client.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+			t.Errorf("GetWorklog() error = %v, want 401 APIError", err)
+		}
+	})
+
+	t.Run("Injected failure", func(t *testing.T) {
+		server := newServer(t)
+		trackerClient := server.NewTrackerClient()
+		server.InjectFailure(trackertest.Failure{Path: "/worklog", Status: http.StatusServiceUnavailable, Times: 1})
+
+		if _, err := trackerClient.GetWorklog("alice", createdAt); err == nil {
+			t.Error("GetWorklog() error = nil, want injected failure")
+		}
+		if _, err := trackerClient.GetWorklog("alice", createdAt); err != nil {
+			t.Errorf("GetWorklog() after failure error = %v", err)
+		}
+	})
+
+	t.Run("Sends auth headers", func(t *testing.T) {
+		server := newServer(t)
+		server.NewTrackerClient().GetWorklog("alice", createdAt)
+		header := server.Requests()[0].Header
+		if header.Get("Authorization") != "Bearer "+trackertest.Token || header.Get("X-Cloud-Org-ID") != trackertest.OrgID {
+			t.Errorf("request headers = %v", header)
+		}
+	})
+}
+
+func TestWorklogCRUD(t *testing.T) {
+	server := trackertest.NewServer()
+	defer server.Close()
+	trackerClient := server.NewTrackerClient()
+
+	start := time.Date(2025, 5, 5, 12, 0, 0, 0, time.UTC)
+	created, err := trackerClient.CreateWorklog("TEST-1", start, 90*time.Minute, "Разработка")
+	if err != nil {
+		t.Fatalf("CreateWorklog() error = %v", err)
+	}
+	if created.Duration != "PT1H30M" || created.Issue.Key != "TEST-1" || created.Comment != "Разработка" {
+		t.Errorf("CreateWorklog() = %+v", created)
+	}
+
+	// запись, добавленная в демо-режиме, видна в отчете пользователя
+	server.User = "alice"
+	if created, err := trackerClient.CreateWorklog("TEST-1", start, time.Hour, ""); err != nil || created.CreatedBy.Id != "alice" {
+		t.Errorf("CreateWorklog() as alice = %+v, %v", created, err)
+	}
+	if err := trackerClient.DeleteWorklog("TEST-1", created.ID+1); err != nil {
+		t.Fatalf("DeleteWorklog() error = %v", err)
+	}
+
+	updated, err := trackerClient.UpdateWorklog("TEST-1", created.ID, created.Version, time.Time{}, 2*time.Hour, "Ревью")
+	if err != nil {
+		t.Fatalf("UpdateWorklog() error = %v", err)
+	}
+	if updated.Duration != "PT2H" || updated.Comment != "Ревью" || updated.Version != created.Version+1 {
+		t.Errorf("UpdateWorklog() = %+v", updated)
+	}
+
+	_, err = trackerClient.UpdateWorklog("TEST-1", created.ID, created.Version, time.Time{}, time.Hour, "")
+	if !errors.Is(err, tracker.ErrVersionConflict) {
+		t.Errorf("UpdateWorklog() with stale version error = %v, want ErrVersionConflict", err)
+	}
+
+	if err := trackerClient.DeleteWorklog("TEST-1", created.ID); err != nil {
+		t.Fatalf("DeleteWorklog() error = %v", err)
+	}
+	if worklogs := server.Worklogs(); len(worklogs) != 0 {
+		t.Errorf("worklogs after delete = %v", worklogs)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "PT0M"},
+		{45 * time.Minute, "PT45M"},
+		{2 * time.Hour, "PT2H"},
+		{26*time.Hour + 5*time.Minute, "PT26H5M"},
+		{90 * time.Second, "PT1M30S"},
+		{30 * time.Second, "PT30S"},
+		{time.Hour + 5*time.Second, "PT1H5S"},
+		{500 * time.Millisecond, "PT0M"},
+	}
+	for _, tt := range tests {
+		if got := tracker.FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package worklog
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"example.com/tracker/internal/store"
+	"example.com/tracker/internal/tracker"
+)
+
+// Ответы JSON API. Длительности (sum, daysSum) передаются в наносекундах.
+type apiTimeSpan struct {
+	Title string `json:"title"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+type apiQuery struct {
+	CreatedBy string      `json:"createdBy"`
+	CreatedAt apiTimeSpan `json:"createdAt"`
+	Show      apiTimeSpan `json:"show"`
+	Timezone  string      `json:"timezone"`
+}
+type apiWorklogResponse struct {
+	Query    apiQuery          `json:"query"`
+	Table    TableData         `json:"table"`
+	Worklogs []tracker.Worklog `json:"worklogs"`
+	// изменения, ожидающие отправки в Трекер
+	Pending []store.Operation `json:"pending,omitempty"`
+}
+type apiError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+type apiErrorResponse struct {
+	Error apiError `json:"error"`
+}
+
+func (h *Handler) worklogAPIHandler(queryFn func(p PathParams) (*Query[time.Time], error), view worklogView) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := *activatedRoute(r)
+		loc, err := h.location(r, p.CreatedBy)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing timezone: %v", err))
+			return
+		}
+		cal := h.UserCalendar(p.CreatedBy)
+		p.CreatedBy = view.name(r)
+		p.Location = loc
+		q, err := queryFn(p)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error creating worklog query: %v", err))
+			return
+		}
+		opts, err := parseTableOptions(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing table options: %v", err))
+			return
+		}
+		opts.norms = !view.showAuthor
+		opts.calendar = cal
+		worklogs, table, err := h.getWorklogsTable(view.load(h, r), q.CreatedAt, q.Show, opts)
+		if err != nil {
+			writeJSONError(w, errorStatus(err), fmt.Sprintf("Error getting worklogs: %v", err))
+			return
+		}
+		if worklogs == nil {
+			worklogs = Worklogs{}
+		}
+
+		writeJSON(w, http.StatusOK, apiWorklogResponse{
+			Query: apiQuery{
+				CreatedBy: q.CreatedBy,
+				CreatedAt: newAPITimeSpan(q.CreatedAt),
+				Show:      newAPITimeSpan(q.Show),
+				Timezone:  q.Timezone,
+			},
+			Table:    table,
+			Worklogs: []tracker.Worklog(worklogs),
+			Pending:  h.pendingOperations(),
+		})
+	}
+}
+
+func newAPITimeSpan(t titledTimeSpan[time.Time]) apiTimeSpan {
+	return apiTimeSpan{
+		Title: t.Title,
+		Start: t.Timespan.Start.Format(time.DateOnly),
+		End:   t.Timespan.End.Format(time.DateOnly),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiErrorResponse{apiError{status, message}})
+}
@@ -0,0 +1,97 @@
+package worklog
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"example.com/tracker/internal/store"
+	"example.com/tracker/internal/tracker"
+	"github.com/AianaM/timefns"
+)
+
+const refreshParam = "refresh"
+
+// errOffline — Трекер недоступен, записи взяты из кэша
+var errOffline = errors.New("tracker is unavailable, using cached worklogs")
+
+// refreshRequested — пользователь нажал «Обновить»: кэш периода загружается заново
+func refreshRequested(r *http.Request) bool {
+	return r.URL.Query().Get(refreshParam) != ""
+}
+
+// withoutRefresh — адрес страницы без refresh, остальные параметры сохраняются
+func withoutRefresh(u *url.URL) string {
+	query := u.Query()
+	query.Del(refreshParam)
+	if len(query) == 0 {
+		return u.Path
+	}
+	return u.Path + "?" + query.Encode()
+}
+
+// getWorklogs возвращает записи автора из кэша, если он настроен, и время синхронизации.
+// Незагруженные дни, refresh и кэш старше CacheMaxAge загружаются из Трекера
+// целиком и заменяют дни в кэше, в том числе за прошедшие периоды: Трекер
+// не отдает удаленные записи, а по дате создания не видно правок старых,
+// поэтому догрузка только новых записей оставила бы в кэше удаленные записи
+// и устаревшие версии.
+// Если Трекер недоступен, возвращаются записи из кэша вместе с ошибкой errOffline.
+func (h *Handler) getWorklogs(createdBy string, createdAt timefns.TimeSpan, refresh bool) ([]tracker.Worklog, time.Time, error) {
+	cache := h.config.Store
+	if cache == nil {
+		worklogs, err := h.trackerClient.GetWorklog(createdBy, createdAt)
+		return worklogs, time.Time{}, err
+	}
+
+	// изменения, сделанные без связи, отправляются до загрузки, чтобы не затереть их в кэше
+	if len(cache.Queue()) > 0 {
+		if err := h.ReplayQueue(); err != nil {
+			log.Println(err)
+		}
+	}
+
+	days := store.Days(createdAt)
+	cached, syncedAt, ok := cache.Get(createdBy, createdAt)
+	now := time.Now()
+	if ok && !refresh && now.Sub(syncedAt) < h.config.CacheMaxAge {
+		return cached, syncedAt, nil
+	}
+	loaded, err := h.trackerClient.GetWorklog(createdBy, days)
+	if ok && isStale(err) {
+		return cached, syncedAt, fmt.Errorf("%w: %w", errOffline, err)
+	} else if err != nil {
+		return nil, time.Time{}, err
+	}
+	if err := cache.Replace(createdBy, days, loaded, now); err != nil {
+		log.Println("Error saving worklogs to store:", err)
+	}
+
+	worklogs, syncedAt, ok := cache.Get(createdBy, createdAt)
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("error reading worklogs from store")
+	}
+	return worklogs, syncedAt, nil
+}
+
+// cachePut и cacheDelete переносят в кэш изменения, сделанные через приложение
+func (h *Handler) cachePut(w tracker.Worklog) {
+	if h.config.Store == nil {
+		return
+	}
+	if err := h.config.Store.Put(w); err != nil {
+		log.Println("Error saving worklog to store:", err)
+	}
+}
+
+func (h *Handler) cacheDelete(issueKey string, id int) {
+	if h.config.Store == nil {
+		return
+	}
+	if err := h.config.Store.Delete(issueKey, id); err != nil {
+		log.Println("Error deleting worklog from store:", err)
+	}
+}
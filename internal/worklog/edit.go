@@ -0,0 +1,192 @@
+package worklog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/tracker/internal/store"
+	"example.com/tracker/internal/tracker"
+	"github.com/AianaM/durationiso8601"
+)
+
+const (
+	issueKeyParam  = "issueKey"
+	worklogIdParam = "worklogId"
+)
+
+// тело запросов на добавление и изменение записи
+type worklogInput struct {
+	Date     string `json:"date"`
+	Duration string `json:"duration"`
+	Comment  string `json:"comment"`
+	Version  int    `json:"version"`
+	// часовой пояс отчета, в котором выбран день; пустой — пояс по умолчанию
+	Timezone string `json:"timezone"`
+}
+
+func (h *Handler) setupEditRoutes(mux *http.ServeMux) {
+	issuePath := pathPrefix + "/issues/{" + issueKeyParam + "}"
+	mux.HandleFunc("POST "+issuePath, h.createWorklogHandler)
+	mux.HandleFunc("PATCH "+issuePath+"/{"+worklogIdParam+"}", h.updateWorklogHandler)
+	mux.HandleFunc("DELETE "+issuePath+"/{"+worklogIdParam+"}", h.deleteWorklogHandler)
+	mux.HandleFunc("DELETE "+pathPrefix+"/pending/{"+operationIdParam+"}", h.discardOperationHandler)
+}
+
+func (h *Handler) createWorklogHandler(w http.ResponseWriter, r *http.Request) {
+	input, err := decodeWorklogInput(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error decoding worklog: %v", err))
+		return
+	}
+	loc := h.UserLocation("")
+	if input.Timezone != "" {
+		if loc, err = time.LoadLocation(input.Timezone); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing timezone: %v", err))
+			return
+		}
+	}
+	start, err := parseWorklogDate(input.Date, loc)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing worklog date: %v", err))
+		return
+	}
+	duration, err := parseDurationInput(input.Duration)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing worklog duration: %v", err))
+		return
+	}
+
+	worklog, err := h.trackerClient.CreateWorklog(r.PathValue(issueKeyParam), start, duration, input.Comment)
+	if op, queued, queueErr := h.enqueue(err, store.Operation{
+		Kind:     store.OperationCreate,
+		IssueKey: r.PathValue(issueKeyParam),
+		Start:    start,
+		Duration: duration,
+		Comment:  input.Comment,
+	}); queueErr != nil {
+		writeJSONError(w, http.StatusInternalServerError, queueErr.Error())
+		return
+	} else if queued {
+		writeJSON(w, http.StatusAccepted, op)
+		return
+	} else if err != nil {
+		writeJSONError(w, errorStatus(err), fmt.Sprintf("Error creating worklog: %v", err))
+		return
+	}
+	h.cachePut(worklog)
+	writeJSON(w, http.StatusCreated, worklog)
+}
+
+func (h *Handler) updateWorklogHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue(worklogIdParam))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing worklog id: %v", err))
+		return
+	}
+	input, err := decodeWorklogInput(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error decoding worklog: %v", err))
+		return
+	}
+	duration, err := parseDurationInput(input.Duration)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing worklog duration: %v", err))
+		return
+	}
+
+	worklog, err := h.trackerClient.UpdateWorklog(r.PathValue(issueKeyParam), id, input.Version, time.Time{}, duration, input.Comment)
+	if op, queued, queueErr := h.enqueue(err, store.Operation{
+		Kind:      store.OperationUpdate,
+		IssueKey:  r.PathValue(issueKeyParam),
+		WorklogID: id,
+		Version:   input.Version,
+		Duration:  duration,
+		Comment:   input.Comment,
+	}); queueErr != nil {
+		writeJSONError(w, http.StatusInternalServerError, queueErr.Error())
+		return
+	} else if queued {
+		writeJSON(w, http.StatusAccepted, op)
+		return
+	} else if errors.Is(err, tracker.ErrVersionConflict) {
+		writeJSONError(w, http.StatusConflict, "Worklog was changed by someone else, press Refresh to reload worklogs")
+		return
+	} else if err != nil {
+		writeJSONError(w, errorStatus(err), fmt.Sprintf("Error updating worklog: %v", err))
+		return
+	}
+	h.cachePut(worklog)
+	writeJSON(w, http.StatusOK, worklog)
+}
+
+func (h *Handler) deleteWorklogHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue(worklogIdParam))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing worklog id: %v", err))
+		return
+	}
+	err = h.trackerClient.DeleteWorklog(r.PathValue(issueKeyParam), id)
+	if op, queued, queueErr := h.enqueue(err, store.Operation{
+		Kind:      store.OperationDelete,
+		IssueKey:  r.PathValue(issueKeyParam),
+		WorklogID: id,
+	}); queueErr != nil {
+		writeJSONError(w, http.StatusInternalServerError, queueErr.Error())
+		return
+	} else if queued {
+		writeJSON(w, http.StatusAccepted, op)
+		return
+	} else if err != nil {
+		writeJSONError(w, errorStatus(err), fmt.Sprintf("Error deleting worklog: %v", err))
+		return
+	}
+	h.cacheDelete(r.PathValue(issueKeyParam), id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeWorklogInput(r *http.Request) (worklogInput, error) {
+	var input worklogInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		return input, err
+	}
+	return input, nil
+}
+
+// parseWorklogDate возвращает полдень указанного дня, чтобы запись
+// не уехала на соседний день при пересчете часового пояса
+func parseWorklogDate(date string, loc *time.Location) (time.Time, error) {
+	day, err := time.ParseInLocation(time.DateOnly, date, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.Add(12 * time.Hour), nil
+}
+
+// parseDurationInput принимает длительность в формате ISO 8601 (PT1H30M)
+// или в том виде, в котором ее выводит humanize.Duration (1h 30m)
+func parseDurationInput(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var d time.Duration
+	var err error
+	if strings.HasPrefix(strings.ToUpper(s), "P") {
+		d, err = durationiso8601.ParseDuration(time.Now(), strings.ToUpper(s))
+	} else {
+		d, err = time.ParseDuration(strings.ReplaceAll(s, " ", ""))
+	}
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive: %s", s)
+	}
+	// Трекер хранит длительность с точностью до секунды
+	if d%time.Second != 0 {
+		return 0, fmt.Errorf("duration must be a whole number of seconds: %s", s)
+	}
+	return d, nil
+}
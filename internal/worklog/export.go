@@ -0,0 +1,187 @@
+package worklog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/tracker/internal/xlsx"
+	"github.com/AianaM/durationiso8601"
+	"github.com/AianaM/timefns"
+)
+
+const (
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+	// в CSV помещается один лист, ?sheet=worklogs выбирает плоский список записей
+	sheetWorklogs = "worklogs"
+)
+
+// exportTable — лист с таблицей: задачи и записи по строкам, дни по столбцам.
+// При группировке перед задачами группы идет строка с ее итогами; levels —
+// уровень вложенности каждой строки, чтобы в XLSX группы сворачивались.
+func exportTable(t TableData) (rows [][]any, levels []int) {
+	header := []any{"issue", "summary", "comment"}
+	for _, day := range t.Days {
+		header = append(header, day)
+	}
+	rows = [][]any{append(header, "sum")}
+	levels = []int{0}
+
+	for _, section := range t.sections() {
+		level := 0
+		if section.Group != nil {
+			cells := []any{section.Group.Title, nil, fmt.Sprintf("%d issues", len(section.Group.Issues))}
+			for _, d := range section.Group.DaysSum {
+				cells = append(cells, hoursCell(d))
+			}
+			rows = append(rows, append(cells, hours(section.Group.Sum)))
+			levels = append(levels, section.Depth)
+			level = section.Depth + 1
+		}
+		for _, rowspan := range section.Rowspans {
+			for _, row := range rowspan.Rows {
+				cells := []any{rowspan.Issue.Key, rowspan.Issue.Display, row.Comment}
+				for _, duration := range row.Duration {
+					if duration == "" {
+						cells = append(cells, nil)
+					} else {
+						cells = append(cells, hours(row.Sum))
+					}
+				}
+				rows = append(rows, append(cells, hours(row.Sum)))
+				levels = append(levels, level)
+			}
+		}
+	}
+
+	total := []any{"total", nil, nil}
+	for _, d := range t.DaysSum {
+		total = append(total, hours(d))
+	}
+	return append(rows, append(total, hours(t.Sum))), append(levels, 0)
+}
+
+// exportWorklogs — плоский лист, по строке на запись из показываемого периода
+func exportWorklogs(worklogs Worklogs, t TableData, loc *time.Location) ([][]any, error) {
+	rows := [][]any{{"id", "issue", "summary", "date", "hours", "comment", "createdBy", "createdAt"}}
+	days := map[string]bool{}
+	for _, day := range t.Days {
+		days[day] = true
+	}
+
+	for _, w := range worklogs {
+		start, err := timefns.Parse(w.Start)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing date: %w", err)
+		}
+		date := start.In(loc).Format(time.DateOnly)
+		if !days[date] {
+			continue
+		}
+		duration, err := durationiso8601.ParseDuration(start, w.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing duration: %w", err)
+		}
+		rows = append(rows, []any{w.ID, w.Issue.Key, w.Issue.Display, date, hours(duration), w.Comment, w.CreatedBy.Display, w.CreatedAt})
+	}
+	return rows, nil
+}
+
+func (h *Handler) writeExport(w http.ResponseWriter, r *http.Request, q Query[time.Time], load loadFn, opts tableOptions, format string) {
+	worklogs, table, err := h.getWorklogsTable(load, q.CreatedAt, q.Show, opts)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting worklogs: %v", err), errorStatus(err))
+		return
+	}
+	flat, err := exportWorklogs(worklogs, table, q.Show.Timespan.Start.Location())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error exporting worklogs: %v", err), http.StatusInternalServerError)
+		return
+	}
+	filename := fmt.Sprintf("worklog_%s_%s_%s.%s", strings.ReplaceAll(q.CreatedBy, "/", "_"),
+		q.Show.Timespan.Start.Format(time.DateOnly), q.Show.Timespan.End.Format(time.DateOnly), format)
+
+	// файл собирается целиком до ответа: ошибка посреди записи
+	// не должна попасть в уже отправленный файл
+	var body bytes.Buffer
+	var contentType string
+	switch format {
+	case formatCSV:
+		rows, _ := exportTable(table)
+		if r.URL.Query().Get("sheet") == sheetWorklogs {
+			rows = flat
+		}
+		if err := writeCSV(&body, rows); err != nil {
+			http.Error(w, fmt.Sprintf("Error writing csv: %v", err), http.StatusInternalServerError)
+			return
+		}
+		contentType = "text/csv; charset=utf-8"
+	case formatXLSX:
+		rows, levels := exportTable(table)
+		err := xlsx.Write(&body, []xlsx.Sheet{
+			{Name: "Table", Rows: rows, OutlineLevels: levels},
+			{Name: "Worklogs", Rows: flat},
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error writing xlsx: %v", err), http.StatusInternalServerError)
+			return
+		}
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		http.Error(w, fmt.Sprintf("Unknown export format: %s", format), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	// для логинов не в ASCII имя файла кодируется как filename*=utf-8''... (RFC 6266)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Length", strconv.Itoa(body.Len()))
+	if _, err := body.WriteTo(w); err != nil {
+		log.Println("Error writing export:", err)
+	}
+}
+
+func writeCSV(out io.Writer, rows [][]any) error {
+	// BOM, чтобы Excel открыл файл в UTF-8
+	if _, err := io.WriteString(out, "\uFEFF"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(out)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			switch v := cell.(type) {
+			case nil:
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// hoursCell — часы или пустая ячейка, если времени нет
+func hoursCell(d time.Duration) any {
+	if d == 0 {
+		return nil
+	}
+	return hours(d)
+}
+
+// hours переводит длительность в часы с точностью до минуты
+func hours(d time.Duration) float64 {
+	return float64(d.Round(time.Minute)/time.Minute) / 60
+}
@@ -0,0 +1,218 @@
+package worklog
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/tracker/internal/tracker"
+)
+
+const groupByParam = "groupBy"
+
+// Group — группа задач таблицы с итогами по дням. Задача с несколькими
+// компонентами или тегами входит в каждую свою группу, поэтому сумма
+// по группам может быть больше итога таблицы.
+type Group struct {
+	Key   string `json:"key"`
+	Title string `json:"title"`
+	// ключи задач группы, включая задачи вложенных групп
+	Issues  []string        `json:"issues"`
+	Groups  []Group         `json:"groups,omitempty"`
+	DaysSum []time.Duration `json:"daysSum"`
+	Sum     time.Duration   `json:"sum"`
+}
+
+type groupValue struct {
+	key, title string
+}
+
+// groupField — поле задачи, по которому группируется таблица
+type groupField struct {
+	name string
+	// поле в API Трекера; пустое — значение берется из ключа задачи
+	trackerField string
+	values       func(issue tracker.Issue) []groupValue
+	// название группы задач, у которых поле не заполнено
+	empty string
+}
+
+func refValue(ref tracker.Ref, withKey bool) []groupValue {
+	if ref.Id == "" && ref.Key == "" {
+		return nil
+	}
+	if withKey {
+		return []groupValue{{ref.Key, strings.TrimSpace(ref.Key + " " + ref.Display)}}
+	}
+	return []groupValue{{cmp.Or(ref.Id, ref.Key), ref.Display}}
+}
+
+var groupFields = []groupField{
+	{name: "queue", values: func(issue tracker.Issue) []groupValue {
+		queue, _, _ := strings.Cut(issue.Key, "-")
+		return []groupValue{{queue, queue}}
+	}, empty: "No queue"},
+	{name: "project", trackerField: "project", values: func(issue tracker.Issue) []groupValue {
+		return refValue(issue.Project, false)
+	}, empty: "No project"},
+	{name: "component", trackerField: "components", values: func(issue tracker.Issue) []groupValue {
+		var values []groupValue
+		for _, c := range issue.Components {
+			values = append(values, refValue(c, false)...)
+		}
+		return values
+	}, empty: "No component"},
+	{name: "tag", trackerField: "tags", values: func(issue tracker.Issue) []groupValue {
+		var values []groupValue
+		for _, tag := range issue.Tags {
+			values = append(values, groupValue{tag, tag})
+		}
+		return values
+	}, empty: "No tag"},
+	{name: "epic", trackerField: "epic", values: func(issue tracker.Issue) []groupValue {
+		return refValue(issue.Epic, true)
+	}, empty: "No epic"},
+	{name: "parent", trackerField: "parent", values: func(issue tracker.Issue) []groupValue {
+		return refValue(issue.Parent, true)
+	}, empty: "No parent"},
+}
+
+// parseGroupBy разбирает ?groupBy=queue,tag: поля через запятую, от внешней группы к вложенной
+func parseGroupBy(value string) ([]groupField, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var fields []groupField
+	for _, name := range strings.Split(value, ",") {
+		i := slices.IndexFunc(groupFields, func(f groupField) bool { return f.name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown groupBy field: %q", name)
+		}
+		fields = append(fields, groupFields[i])
+	}
+	return fields, nil
+}
+
+// groupTable группирует задачи таблицы. Поля задач, кроме очереди, загружаются
+// из Трекера; если он недоступен, таблица остается без группировки.
+func (h *Handler) groupTable(t *TableData, fields []groupField, order sortOrder) error {
+	if len(fields) == 0 || len(t.Rowspans) == 0 {
+		return nil
+	}
+	if err := h.enrichIssues(t, fields); isStale(err) && t.Offline {
+		log.Println("Error grouping offline table:", err)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error getting issues for grouping: %w", err)
+	}
+	for _, f := range fields {
+		t.GroupBy = append(t.GroupBy, f.name)
+	}
+	t.Groups = t.buildGroups(t.issueKeys(), fields, order)
+	return nil
+}
+
+// enrichIssues дополняет задачи таблицы полями группировки: в записях о
+// затраченном времени у задачи есть только ключ и название
+func (h *Handler) enrichIssues(t *TableData, fields []groupField) error {
+	apiFields := []string{"key", "summary"}
+	for _, f := range fields {
+		if f.trackerField != "" {
+			apiFields = append(apiFields, f.trackerField)
+		}
+	}
+	if len(apiFields) == 2 {
+		return nil
+	}
+	issues, err := h.trackerClient.SearchIssues(tracker.IssueSearch{Keys: t.issueKeys(), Fields: apiFields})
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		rowspan := t.rowspan(issue.Key)
+		if rowspan == nil {
+			continue
+		}
+		issue.Self = cmp.Or(issue.Self, rowspan.Issue.Self)
+		issue.Id = cmp.Or(issue.Id, rowspan.Issue.Id)
+		issue.Display = cmp.Or(issue.Display, rowspan.Issue.Display)
+		rowspan.Issue = issue
+	}
+	return nil
+}
+
+// buildGroups раскладывает задачи keys по значениям первого поля,
+// остальные поля группируют задачи внутри каждой группы. Задачи в группе
+// идут в порядке таблицы.
+func (t TableData) buildGroups(keys []string, fields []groupField, order sortOrder) []Group {
+	field := fields[0]
+	var groups []Group
+	for _, key := range keys {
+		values := field.values(t.rowspan(key).Issue)
+		if len(values) == 0 {
+			values = []groupValue{{"", field.empty}}
+		}
+		for _, v := range values {
+			i := slices.IndexFunc(groups, func(g Group) bool { return g.Key == v.key })
+			if i < 0 {
+				groups = append(groups, Group{Key: v.key, Title: v.title, DaysSum: make([]time.Duration, len(t.Days))})
+				i = len(groups) - 1
+			}
+			if !slices.Contains(groups[i].Issues, key) {
+				groups[i].Issues = append(groups[i].Issues, key)
+			}
+		}
+	}
+
+	for i := range groups {
+		for _, key := range groups[i].Issues {
+			rowspan := t.rowspan(key)
+			for day, d := range rowspan.DaysSum {
+				groups[i].DaysSum[day] += d
+			}
+			groups[i].Sum += rowspan.Sum
+		}
+		if len(fields) > 1 {
+			groups[i].Groups = t.buildGroups(groups[i].Issues, fields[1:], order)
+		}
+	}
+	sortGroups(groups, order)
+	return groups
+}
+
+// tableSection — заголовок группы и задачи сразу под ним, в порядке вывода
+// в HTML и выгрузке. Без группировки таблица — одна секция без заголовка.
+type tableSection struct {
+	Group *Group
+	Depth int
+	// номера групп от внешней к вложенной, например "0.2"
+	Path     string
+	Rowspans []Rowspan
+}
+
+func (t TableData) sections() []tableSection {
+	if len(t.Groups) == 0 {
+		return []tableSection{{Rowspans: t.Rowspans}}
+	}
+	var sections []tableSection
+	var walk func(groups []Group, depth int, path string)
+	walk = func(groups []Group, depth int, path string) {
+		for i := range groups {
+			g := &groups[i]
+			section := tableSection{Group: g, Depth: depth, Path: path + strconv.Itoa(i)}
+			if len(g.Groups) == 0 {
+				for _, key := range g.Issues {
+					section.Rowspans = append(section.Rowspans, *t.rowspan(key))
+				}
+			}
+			sections = append(sections, section)
+			walk(g.Groups, depth+1, section.Path+".")
+		}
+	}
+	walk(t.Groups, 0, "")
+	return sections
+}
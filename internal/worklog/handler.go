@@ -2,24 +2,30 @@ package worklog
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"html/template"
 	"io/fs"
 	"log"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"example.com/tracker/internal/calendar"
+	"example.com/tracker/internal/client"
+	"example.com/tracker/internal/humanize"
+	"example.com/tracker/internal/store"
 	"example.com/tracker/internal/tracker"
 	"github.com/AianaM/timefns"
 )
 
 const (
-	name       = "worklog"
-	pathPrefix = "/" + name
-	isDev      = false
+	name          = "worklog"
+	pathPrefix    = "/" + name
+	apiPathPrefix = "/api/" + name
+	isDev         = false
 )
 
 //go:embed static/*
@@ -33,12 +39,33 @@ type templateConfig struct {
 	funcMap template.FuncMap
 	css     template.CSS
 	tpl     *template.Template
+	teamTpl *template.Template
+}
+type Config struct {
+	// команды: название → логины участников
+	Teams map[string][]string
+	// локальный кэш записей; nil — каждый запрос идет в Трекер
+	Store *store.Store
+	// сколько кэш считается свежим; после этого период загружается из Трекера заново
+	CacheMaxAge time.Duration
+	// часовой пояс отчетов по умолчанию; nil — time.Local
+	Location *time.Location
+	// часовые пояса участников по логину
+	UserLocations map[string]*time.Location
+	// производственный календарь; nil — дни не сравниваются с нормой
+	// и не выделяются в шапке таблицы
+	Calendar *calendar.Calendar
+	// календари команд: общий календарь с днями страны или команды
+	TeamCalendars map[string]*calendar.Calendar
+	Norms         Norms
 }
 type Handler struct {
 	trackerClient *tracker.TrackerClient
 	templates     templateConfig
+	config        Config
+	// очередь изменений отправляется не больше чем одним запросом одновременно
+	replayMu sync.Mutex
 }
-type Preset string
 type timespanParams struct {
 	Preset string
 	From   string
@@ -48,6 +75,8 @@ type PathParams struct {
 	Worklog   timespanParams
 	Show      timespanParams
 	CreatedBy string
+	// часовой пояс, в котором считаются дни периодов; nil — time.Local
+	Location *time.Location
 }
 
 var pathParams = PathParams{
@@ -74,11 +103,17 @@ type titledTimeSpan[T any] struct {
 type Query[T any] struct {
 	CreatedBy       string
 	CreatedAt, Show titledTimeSpan[T]
+	// название часового пояса периодов, например Europe/Moscow
+	Timezone string
 }
 type PageWorklogContent struct {
 	Query    Query[string]
 	Worklogs TableData
-	Style    template.CSS
+	// изменения, ожидающие отправки в Трекер
+	Pending []store.Operation
+	// в отчете записи разных авторов
+	ShowAuthor bool
+	Style      template.CSS
 }
 
 type PageWorklog struct {
@@ -86,37 +121,64 @@ type PageWorklog struct {
 	Content PageWorklogContent
 }
 
-func NewHandler(trackerClient *tracker.TrackerClient, indexTpl *template.Template) (*Handler, error) {
+func NewHandler(trackerClient *tracker.TrackerClient, indexTpl *template.Template, config Config) (*Handler, error) {
 	funcMap := getFuncMap(trackerClient.HostURL)
 	css, err := getStyle()
 	if err != nil {
 		return nil, fmt.Errorf("error getting style: %w", err)
 	}
-	tpl, err := getTpl(funcMap, indexTpl)
+	tpl, err := getTpl(funcMap, indexTpl, "worklog.html")
 	if err != nil {
 		return nil, fmt.Errorf("error getting template: %w", err)
 	}
+	teamTpl, err := getTpl(funcMap, indexTpl, "team.html")
+	if err != nil {
+		return nil, fmt.Errorf("error getting team template: %w", err)
+	}
 
 	tpls := templateConfig{
 		name:    tpl.Name(),
 		funcMap: funcMap,
 		css:     css,
 		tpl:     tpl,
+		teamTpl: teamTpl,
 	}
 
 	return &Handler{
 		trackerClient: trackerClient,
 		templates:     tpls,
+		config:        config,
 	}, nil
 }
 
+// маршруты отчета, общие для HTML-страниц и JSON API
+var worklogRoutes = []struct {
+	path    string
+	queryFn func(p PathParams) (*Query[time.Time], error)
+}{
+	{"/{" + pathParams.CreatedBy + "}/{" + pathParams.Worklog.Preset + "}", worklogQuery},
+	{"/{" + pathParams.CreatedBy + "}/{" + pathParams.Worklog.Preset + "}/show/{" + pathParams.Show.Preset + "}", worklogShowQuery},
+	{"/{" + pathParams.CreatedBy + "}/{" + pathParams.Worklog.Preset + "}/show/from/{" + pathParams.Show.From + "}/to/{" + pathParams.Show.To + "}", worklogShowQuery},
+	{"/{" + pathParams.CreatedBy + "}/from/{" + pathParams.Worklog.From + "}/to/{" + pathParams.Worklog.To + "}", worklogQuery},
+	{"/{" + pathParams.CreatedBy + "}/from/{" + pathParams.Worklog.From + "}/to/{" + pathParams.Worklog.To + "}/show/{" + pathParams.Show.Preset + "}", worklogShowQuery},
+	{"/{" + pathParams.CreatedBy + "}/from/{" + pathParams.Worklog.From + "}/to/{" + pathParams.Worklog.To + "}/show/from/{" + pathParams.Show.From + "}/to/{" + pathParams.Show.To + "}", worklogShowQuery},
+}
+
 func (h *Handler) SetupRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("GET "+pathPrefix+"/{"+pathParams.CreatedBy+"}/{"+pathParams.Worklog.Preset+"}", h.worklogHandler44(worklogQuery))
-	mux.HandleFunc("GET "+pathPrefix+"/{"+pathParams.CreatedBy+"}/{"+pathParams.Worklog.Preset+"}/show/{"+pathParams.Show.Preset+"}", h.worklogHandler44(worklogShowQuery))
-	mux.HandleFunc("GET "+pathPrefix+"/{"+pathParams.CreatedBy+"}/{"+pathParams.Worklog.Preset+"}/show/from/{"+pathParams.Show.From+"}/to/{"+pathParams.Show.To+"}", h.worklogHandler44(worklogShowQuery))
-	mux.HandleFunc("GET "+pathPrefix+"/{"+pathParams.CreatedBy+"}/from/{"+pathParams.Worklog.From+"}/to/{"+pathParams.Worklog.To+"}", h.worklogHandler44(worklogQuery))
-	mux.HandleFunc("GET "+pathPrefix+"/{"+pathParams.CreatedBy+"}/from/{"+pathParams.Worklog.From+"}/to/{"+pathParams.Worklog.To+"}/show/{"+pathParams.Show.Preset+"}", h.worklogHandler44(worklogShowQuery))
-	mux.HandleFunc("GET "+pathPrefix+"/{"+pathParams.CreatedBy+"}/from/{"+pathParams.Worklog.From+"}/to/{"+pathParams.Worklog.To+"}/show/from/{"+pathParams.Show.From+"}/to/{"+pathParams.Show.To+"}", h.worklogHandler44(worklogShowQuery))
+	for _, route := range worklogRoutes {
+		mux.HandleFunc("GET "+pathPrefix+route.path, h.worklogHandler44(route.queryFn, authorView))
+		mux.HandleFunc("GET "+apiPathPrefix+route.path, h.worklogAPIHandler(route.queryFn, authorView))
+
+		// отчеты команды, задачи и очереди: тот же период без автора в пути
+		periodPath := strings.TrimPrefix(route.path, "/{"+pathParams.CreatedBy+"}")
+		mux.HandleFunc("GET "+teamPathPrefix+periodPath, h.teamHandler(route.queryFn))
+		for _, view := range []worklogView{issueView, queueView} {
+			mux.HandleFunc("GET "+pathPrefix+view.path+periodPath, h.worklogHandler44(route.queryFn, view))
+			mux.HandleFunc("GET "+apiPathPrefix+view.path+periodPath, h.worklogAPIHandler(route.queryFn, view))
+		}
+	}
+	mux.HandleFunc("GET "+pathPrefix+issueView.path, h.issueAllTimeHandler)
+	h.setupEditRoutes(mux)
 }
 
 func (h *Handler) HandleStatic(mux *http.ServeMux) {
@@ -146,7 +208,11 @@ func worklogQuery(p PathParams) (*Query[time.Time], error) {
 	if p.CreatedBy == "" {
 		return nil, fmt.Errorf("Error parsing CreatedBy parameter")
 	}
-	createdAt, err := p.Worklog.parse()
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	createdAt, err := p.Worklog.parse(loc)
 	if err != nil {
 		return nil, fmt.Errorf("Error parsing worklog parameters: %v", err)
 	}
@@ -154,6 +220,7 @@ func worklogQuery(p PathParams) (*Query[time.Time], error) {
 		CreatedBy: p.CreatedBy,
 		CreatedAt: createdAt,
 		Show:      createdAt,
+		Timezone:  loc.String(),
 	}, nil
 }
 
@@ -162,7 +229,7 @@ func worklogShowQuery(p PathParams) (*Query[time.Time], error) {
 	if err != nil {
 		return nil, fmt.Errorf("Error creating worklog query: %v", err)
 	}
-	show, err := p.Show.parse()
+	show, err := p.Show.parse(q.CreatedAt.Timespan.Start.Location())
 	if err != nil {
 		return nil, fmt.Errorf("Error parsing show parameters: %v", err)
 	}
@@ -170,17 +237,42 @@ func worklogShowQuery(p PathParams) (*Query[time.Time], error) {
 	return q, nil
 }
 
-func (h *Handler) worklogHandler44(queryFn func(p PathParams) (*Query[time.Time], error)) func(http.ResponseWriter, *http.Request) {
+func (h *Handler) worklogHandler44(queryFn func(p PathParams) (*Query[time.Time], error), view worklogView) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		activatedRoute := activatedRoute(r)
+		loc, err := h.location(r, activatedRoute.CreatedBy)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error parsing timezone: %v", err), http.StatusBadRequest)
+			return
+		}
+		cal := h.UserCalendar(activatedRoute.CreatedBy)
+		activatedRoute.CreatedBy = view.name(r)
+		activatedRoute.Location = loc
 		q, err := queryFn(*activatedRoute)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Error creating worklog query: %v", err), http.StatusInternalServerError)
 			return
 		}
-		page, err := h.createWorklogPage(*q)
+		opts, err := parseTableOptions(r)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Error creating worklog page: %v", err), http.StatusInternalServerError)
+			http.Error(w, fmt.Sprintf("Error parsing table options: %v", err), http.StatusBadRequest)
+			return
+		}
+		opts.norms = !view.showAuthor
+		opts.calendar = cal
+		if format := r.URL.Query().Get("format"); format != "" {
+			h.writeExport(w, r, *q, view.load(h, r), opts, format)
+			return
+		}
+		page, err := h.createWorklogPage(*q, view.load(h, r), opts)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error creating worklog page: %v", err), errorStatus(err))
+			return
+		}
+		page.Content.ShowAuthor = view.showAuthor
+		if opts.refresh && !page.Content.Worklogs.Offline {
+			// после обновления кэша — на обычный адрес, чтобы перезагрузка страницы не повторяла запрос
+			http.Redirect(w, r, withoutRefresh(r.URL), http.StatusSeeOther)
 			return
 		}
 
@@ -191,39 +283,28 @@ func (h *Handler) worklogHandler44(queryFn func(p PathParams) (*Query[time.Time]
 	}
 }
 
-func (p Preset) parsePresetTimespan() (titledTimeSpan[time.Time], error) {
-	switch p {
-	case "today":
-		return titledTimeSpan[time.Time]{"Сегодня", timefns.Today()}, nil
-	case "currentWeek":
-		return titledTimeSpan[time.Time]{"Текущая неделя", timefns.CurrentWeek()}, nil
-	case "currentMonth":
-		return titledTimeSpan[time.Time]{"Текущий месяц", timefns.CurrentMonth()}, nil
-	default:
-		return titledTimeSpan[time.Time]{}, fmt.Errorf("unknown preset: %s", p)
-	}
-}
-
-func parseTimeSpan(start, end string) (timefns.TimeSpan, error) {
-	startDate, err := time.Parse(time.DateOnly, start)
+func parseTimeSpan(start, end string, loc *time.Location) (timefns.TimeSpan, error) {
+	startDate, err := time.ParseInLocation(time.DateOnly, start, loc)
 	if err != nil {
 		return timefns.TimeSpan{}, fmt.Errorf("error parsing start date: %w", err)
 	}
-	endDate, err := time.Parse(time.DateOnly, end)
+	endDate, err := time.ParseInLocation(time.DateOnly, end, loc)
 	if err != nil {
 		return timefns.TimeSpan{}, fmt.Errorf("error parsing end date: %w", err)
 	}
 	return timefns.TimeSpan{Start: startDate, End: endDate}, nil
 }
-func (t timespanParams) parse() (titledTimeSpan[time.Time], error) {
+
+// parse возвращает период, дни которого начинаются в полночь в поясе loc
+func (t timespanParams) parse(loc *time.Location) (titledTimeSpan[time.Time], error) {
 	if t.Preset != "" {
-		if worklog, err := Preset(t.Preset).parsePresetTimespan(); err != nil {
+		if worklog, err := Preset(t.Preset).timespan(time.Now().In(loc)); err != nil {
 			return titledTimeSpan[time.Time]{}, fmt.Errorf("error parsing preset %v: %v", t, err)
 		} else {
 			return worklog, nil
 		}
 	} else if t.From != "" && t.To != "" {
-		if worklog, err := parseTimeSpan(t.From, t.To); err != nil {
+		if worklog, err := parseTimeSpan(t.From, t.To, loc); err != nil {
 			return titledTimeSpan[time.Time]{}, fmt.Errorf("error parsing custom %v: %v", t, err)
 		} else {
 			return titledTimeSpan[time.Time]{"Кастомный", worklog}, nil
@@ -232,8 +313,8 @@ func (t timespanParams) parse() (titledTimeSpan[time.Time], error) {
 	return titledTimeSpan[time.Time]{}, fmt.Errorf("error parsing worklog path: %v", t)
 }
 
-func (h *Handler) createWorklogPage(q Query[time.Time]) (PageWorklog, error) {
-	worklogsTable, err := h.getWorklogsTable(q.CreatedBy, q.CreatedAt, q.Show)
+func (h *Handler) createWorklogPage(q Query[time.Time], load loadFn, opts tableOptions) (PageWorklog, error) {
+	_, worklogsTable, err := h.getWorklogsTable(load, q.CreatedAt, q.Show, opts)
 	if err != nil {
 		return PageWorklog{}, fmt.Errorf("error getting worklogs: %w", err)
 	}
@@ -242,47 +323,69 @@ func (h *Handler) createWorklogPage(q Query[time.Time]) (PageWorklog, error) {
 	return PageWorklog{
 		Title: "Worklog: " + q.Show.Title,
 		Content: PageWorklogContent{
-			Query: Query[string]{
-				CreatedBy: q.CreatedBy,
-				CreatedAt: titledTimeSpan[string]{
-					Title: q.CreatedAt.Title,
-					Timespan: struct {
-						Start string
-						End   string
-					}{Start: q.CreatedAt.Timespan.Start.Format(time.DateOnly), End: q.CreatedAt.Timespan.End.Format(time.DateOnly)}},
-				Show: titledTimeSpan[string]{
-					Title: q.Show.Title,
-					Timespan: struct {
-						Start string
-						End   string
-					}{Start: q.Show.Timespan.Start.Format(time.DateOnly), End: q.Show.Timespan.End.Format(time.DateOnly)}},
-			},
+			Query:    formatQuery(q),
 			Worklogs: worklogsTable,
+			Pending:  h.pendingOperations(),
 			Style:    h.templates.css,
 		}}, nil
 }
-func DurationBeautify(d time.Duration) string {
-	h := int(d / time.Hour)
-	m := int((d % time.Hour) / time.Minute)
 
-	parts := make([]string, 0, 2)
-	if h > 0 {
-		parts = append(parts, strconv.Itoa(h)+"h")
+func formatQuery(q Query[time.Time]) Query[string] {
+	return Query[string]{
+		CreatedBy: q.CreatedBy,
+		CreatedAt: formatTimeSpan(q.CreatedAt),
+		Show:      formatTimeSpan(q.Show),
+		Timezone:  q.Timezone,
 	}
-
-	if m > 0 {
-		parts = append(parts, strconv.Itoa(m)+"m")
+}
+func formatTimeSpan(t titledTimeSpan[time.Time]) titledTimeSpan[string] {
+	return titledTimeSpan[string]{
+		Title: t.Title,
+		Timespan: struct {
+			Start string
+			End   string
+		}{Start: t.Timespan.Start.Format(time.DateOnly), End: t.Timespan.End.Format(time.DateOnly)},
 	}
+}
 
-	if len(parts) == 0 {
-		return "0m"
+// errorStatus подбирает код ответа по ошибке: ошибки клиента из Трекера
+// (401, 403, 404 и т.п.) передаются как есть, остальные превращаются в 500
+func errorStatus(err error) int {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 {
+		return apiErr.StatusCode
 	}
-
-	return strings.Join(parts, " ")
+	return http.StatusInternalServerError
 }
+
 func getFuncMap(hostURL string) template.FuncMap {
 	return map[string]interface{}{
-		"durationBeautify": DurationBeautify,
+		"durationBeautify": humanize.Duration,
+		"since":            func(t time.Time) string { return humanize.Duration(time.Since(t)) },
+		"inc":              func(i int) int { return i + 1 },
+		"tableSections":    TableData.sections,
+		"dayKind": func(kinds []calendar.Kind, i int) calendar.Kind {
+			if i >= len(kinds) {
+				return ""
+			}
+			return kinds[i]
+		},
+		"dayNorm": func(norms []DayNorm, i int) DayNorm {
+			if i >= len(norms) {
+				return DayNorm{}
+			}
+			return norms[i]
+		},
+		"sortOrders":      func() []sortOrder { return sortOrders },
+		"headerPresets":   headerPresets,
+		"startWindowNote": tracker.StartWindowNote,
+		"groupByFields": func() []string {
+			names := make([]string, len(groupFields))
+			for i, f := range groupFields {
+				names[i] = f.name
+			}
+			return names
+		},
 		"trackerUrl": func(issueKey string) string {
 			if hostURL == "" {
 				return ""
@@ -307,12 +410,12 @@ func getStyle() (template.CSS, error) {
 	}
 	return template.CSS(style), nil
 }
-func getTpl(funcMap template.FuncMap, indexTpl *template.Template) (*template.Template, error) {
+func getTpl(funcMap template.FuncMap, indexTpl *template.Template, name string) (*template.Template, error) {
 	var w *template.Template
 	if isDev {
-		w = template.Must(template.Must(indexTpl.Clone()).New("worklog.html").Funcs(funcMap).ParseFiles("internal/worklog/templates/worklog.html"))
+		w = template.Must(template.Must(indexTpl.Clone()).New(name).Funcs(funcMap).ParseFiles("internal/worklog/templates/header.html", "internal/worklog/templates/"+name))
 	} else {
-		w = template.Must(template.Must(indexTpl.Clone()).New("worklog.html").Funcs(funcMap).ParseFS(TemplatesFs, "templates/worklog.html"))
+		w = template.Must(template.Must(indexTpl.Clone()).New(name).Funcs(funcMap).ParseFS(TemplatesFs, "templates/header.html", "templates/"+name))
 	}
 
 	return w.Lookup("index.html"), nil
@@ -0,0 +1,736 @@
+package worklog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"example.com/tracker/internal/calendar"
+	"example.com/tracker/internal/client"
+	"example.com/tracker/internal/store"
+	"example.com/tracker/internal/tracker"
+	"example.com/tracker/internal/tracker/trackertest"
+	"example.com/tracker/web"
+	"github.com/AianaM/timefns"
+)
+
+func newTestHandler(t *testing.T, config Config) (*trackertest.Server, *http.ServeMux) {
+	t.Helper()
+	server := trackertest.NewServer()
+	t.Cleanup(server.Close)
+	alice := tracker.User{Id: "alice", Display: "alice"}
+	server.Seed(
+		tracker.Worklog{ID: 1, Issue: tracker.Issue{Key: "TEST-1", Display: "Первая задача"}, Comment: "Разработка", CreatedBy: alice,
+			CreatedAt: "2025-05-05T18:00:00.000+0300", Start: "2025-05-05T10:00:00.000+0300", Duration: "PT4H"},
+		tracker.Worklog{ID: 2, Issue: tracker.Issue{Key: "TEST-2", Display: "Вторая задача"}, Comment: "Ревью", CreatedBy: alice,
+			CreatedAt: "2025-05-06T18:00:00.000+0300", Start: "2025-05-06T14:00:00.000+0300", Duration: "PT1H30M"},
+		tracker.Worklog{ID: 3, Issue: tracker.Issue{Key: "TEST-1", Display: "Первая задача"}, Comment: "Разработка", CreatedBy: alice,
+			CreatedAt: "2025-05-07T18:00:00.000+0300", Start: "2025-05-06T10:00:00.000+0300", Duration: "PT2H"},
+	)
+
+	indexTpl := template.Must(template.ParseFS(web.Templates, "templates/index.html"))
+	h, err := NewHandler(server.NewTrackerClient(), indexTpl, config)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	mux := http.NewServeMux()
+	h.SetupRoutes(mux)
+	return server, mux
+}
+
+var testConfig = Config{Teams: map[string][]string{"dev": {"alice", "bob"}}}
+
+func serve(mux *http.ServeMux, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestWorklogAPI(t *testing.T) {
+	_, mux := newTestHandler(t, testConfig)
+
+	rec := serve(mux, http.MethodGet, "/api/worklog/alice/from/2025-05-01/to/2025-06-01/show/from/2025-05-05/to/2025-05-08", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+	}
+	var resp apiWorklogResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Worklogs) != 3 {
+		t.Errorf("worklogs = %d, want 3", len(resp.Worklogs))
+	}
+	if resp.Table.Sum != 7*time.Hour+30*time.Minute {
+		t.Errorf("table sum = %v, want 7h30m", resp.Table.Sum)
+	}
+	if got := resp.Table.Rowspans[0].DaysSum; resp.Table.Rowspans[0].Issue.Key != "TEST-1" || len(got) != 3 || got[0] != 4*time.Hour || got[1] != 2*time.Hour {
+		t.Errorf("TEST-1 days sum = %v", got)
+	}
+	if resp.Query.Show.Start != "2025-05-05" || resp.Query.CreatedBy != "alice" {
+		t.Errorf("query = %+v", resp.Query)
+	}
+}
+
+func TestWorklogAPIErrors(t *testing.T) {
+	server, mux := newTestHandler(t, testConfig)
+
+	rec := serve(mux, http.MethodGet, "/api/worklog/alice/unknownPreset", "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"error"`) {
+		t.Errorf("unknown preset: status = %v, body = %s", rec.Code, rec.Body)
+	}
+
+	server.InjectFailure(trackertest.Failure{Path: "/worklog", Status: http.StatusForbidden, Times: 1})
+	rec = serve(mux, http.MethodGet, "/api/worklog/alice/from/2025-05-01/to/2025-06-01", "")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("tracker 403: status = %v, body = %s", rec.Code, rec.Body)
+	}
+}
+
+func TestWorklogPage(t *testing.T) {
+	_, mux := newTestHandler(t, testConfig)
+
+	rec := serve(mux, http.MethodGet, "/worklog/alice/from/2025-05-01/to/2025-06-01/show/from/2025-05-05/to/2025-05-08", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+	}
+	for _, want := range []string{"TEST-1", "Вторая задача", `data-day="2025-05-06"`, `data-preset="last7days"`, `title="` + tracker.StartWindowNote() + `"`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+
+	rec = serve(mux, http.MethodGet, "/worklog/alice/from/2025-05-01/to/2025-06-01/show/from/2025-05-05/to/2025-05-08?format=csv", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "TEST-2,Вторая задача,Ревью,,1.5,,1.5") {
+		t.Errorf("csv export: status = %v, body = %s", rec.Code, rec.Body)
+	}
+
+	// имя файла с логином не в ASCII кодируется по RFC 6266
+	rec = serve(mux, http.MethodGet, "/worklog/"+url.PathEscape("иван")+"/from/2025-05-01/to/2025-06-01?format=xlsx", "")
+	_, params, err := mime.ParseMediaType(rec.Header().Get("Content-Disposition"))
+	if rec.Code != http.StatusOK || err != nil || params["filename"] != "worklog_иван_2025-05-01_2025-06-01.xlsx" {
+		t.Errorf("xlsx export: status = %v, Content-Disposition = %q", rec.Code, rec.Header().Get("Content-Disposition"))
+	}
+}
+
+func TestTeamPage(t *testing.T) {
+	server, mux := newTestHandler(t, testConfig)
+	server.InjectFailure(trackertest.Failure{Path: "/worklog", Status: http.StatusNotFound})
+
+	rec := serve(mux, http.MethodGet, "/worklog/team/dev/from/2025-05-01/to/2025-06-01", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), "Не удалось загрузить записи") {
+		t.Errorf("team page does not report failed users")
+	}
+
+	if rec := serve(mux, http.MethodGet, "/worklog/team/unknown/today", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown team: status = %v", rec.Code)
+	}
+}
+
+func TestEditWorklog(t *testing.T) {
+	server, mux := newTestHandler(t, testConfig)
+
+	rec := serve(mux, http.MethodPost, "/worklog/issues/TEST-3", `{"date":"2025-05-07","duration":"1h 30m","comment":"Новая"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	var created tracker.Worklog
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.Duration != "PT1H30M" {
+		t.Errorf("created duration = %v", created.Duration)
+	}
+
+	rec = serve(mux, http.MethodPatch, "/worklog/issues/TEST-2/2", `{"duration":"PT2H","comment":"Ревью","version":1}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("update: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	rec = serve(mux, http.MethodPatch, "/worklog/issues/TEST-2/2", `{"duration":"PT3H","comment":"Ревью","version":1}`)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("stale update: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	rec = serve(mux, http.MethodPatch, "/worklog/issues/TEST-2/2", `{"duration":"soon","version":2}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid duration: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	rec = serve(mux, http.MethodPatch, "/worklog/issues/TEST-2/2", `{"duration":"1.5s","version":2}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("fractional seconds: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	// секунды не теряются
+	rec = serve(mux, http.MethodPatch, "/worklog/issues/TEST-2/2", `{"duration":"90s","version":2}`)
+	var updated tracker.Worklog
+	if err := json.Unmarshal(rec.Body.Bytes(), &updated); err != nil || rec.Code != http.StatusOK || updated.Duration != "PT1M30S" {
+		t.Errorf("update in seconds: status = %v, body = %s", rec.Code, rec.Body)
+	}
+
+	if rec := serve(mux, http.MethodDelete, "/worklog/issues/TEST-1/1", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	if rec := serve(mux, http.MethodDelete, "/worklog/issues/TEST-1/1", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("delete missing: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	if got := len(server.Worklogs()); got != 3 {
+		t.Errorf("worklogs after edits = %d, want 3", got)
+	}
+}
+
+func TestWorklogCache(t *testing.T) {
+	worklogStore, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, mux := newTestHandler(t, Config{Store: worklogStore, CacheMaxAge: time.Hour})
+	const path = "/api/worklog/alice/from/2025-05-01/to/2025-06-01"
+	sum := func() time.Duration {
+		t.Helper()
+		rec := serve(mux, http.MethodGet, path, "")
+		var resp apiWorklogResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+		}
+		if resp.Table.SyncedAt.IsZero() {
+			t.Error("syncedAt is not set")
+		}
+		return resp.Table.Sum
+	}
+
+	sum()
+	sum()
+	if requests := len(server.Requests()); requests != 1 {
+		t.Errorf("requests to tracker = %d, want 1", requests)
+	}
+
+	// изменения через приложение попадают в кэш без запроса в Трекер
+	if rec := serve(mux, http.MethodDelete, "/worklog/issues/TEST-2/2", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	if got := sum(); got != 6*time.Hour {
+		t.Errorf("sum after delete = %v, want 6h", got)
+	}
+
+	// записи, добавленные в обход приложения, видны после обновления
+	server.Seed(tracker.Worklog{Issue: tracker.Issue{Key: "TEST-3"}, CreatedBy: tracker.User{Id: "alice"},
+		CreatedAt: "2025-05-08T18:00:00.000+0300", Start: "2025-05-08T10:00:00.000+0300", Duration: "PT1H"})
+	if got := sum(); got != 6*time.Hour {
+		t.Errorf("cached sum = %v, want 6h", got)
+	}
+	rec := serve(mux, http.MethodGet, "/worklog/alice/from/2025-05-01/to/2025-06-01?refresh=1", "")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/worklog/alice/from/2025-05-01/to/2025-06-01" {
+		t.Errorf("refresh: status = %v, location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if got := sum(); got != 7*time.Hour {
+		t.Errorf("sum after refresh = %v, want 7h", got)
+	}
+}
+
+func TestWorklogCacheStale(t *testing.T) {
+	worklogStore, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, mux := newTestHandler(t, Config{Store: worklogStore, CacheMaxAge: time.Hour})
+	const path = "/api/worklog/alice/from/2025-05-01/to/2025-06-01"
+	get := func() apiWorklogResponse {
+		t.Helper()
+		rec := serve(mux, http.MethodGet, path, "")
+		var resp apiWorklogResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+		}
+		return resp
+	}
+	get()
+
+	// записи изменили и удалили в Трекере после синхронизации
+	client := server.NewTrackerClient()
+	if _, err := client.UpdateWorklog("TEST-1", 1, 1, time.Time{}, 5*time.Hour, "Правка в Трекере"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteWorklog("TEST-1", 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := get().Table.Sum; got != 7*time.Hour+30*time.Minute {
+		t.Errorf("fresh cache sum = %v, want cached 7h30m", got)
+	}
+
+	// кэш устарел: дни прошедшего периода загружаются заново
+	period := timefns.TimeSpan{Start: time.Date(2025, 5, 1, 0, 0, 0, 0, time.Local), End: time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)}
+	cached, _, _ := worklogStore.Get("alice", period)
+	if err := worklogStore.Replace("alice", period, cached, time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	resp := get()
+	if resp.Table.Sum != 6*time.Hour+30*time.Minute {
+		t.Errorf("stale cache sum = %v, want 6h30m", resp.Table.Sum)
+	}
+	rowspan := resp.Table.rowspan("TEST-1")
+	if rowspan == nil || len(rowspan.Rows) != 1 || rowspan.Rows[0].Version != 2 || rowspan.Rows[0].Comment != "Правка в Трекере" {
+		t.Errorf("TEST-1 after resync = %+v, want the edited worklog only", rowspan)
+	}
+}
+
+func TestOfflineMode(t *testing.T) {
+	worklogStore, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, mux := newTestHandler(t, Config{Store: worklogStore})
+	const path = "/api/worklog/alice/from/2025-05-01/to/2025-06-01"
+	get := func(query string) apiWorklogResponse {
+		t.Helper()
+		rec := serve(mux, http.MethodGet, path+query, "")
+		var resp apiWorklogResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+		}
+		return resp
+	}
+	get("")
+
+	// Трекер недоступен: записи из кэша, изменения в очереди
+	server.InjectFailure(trackertest.Failure{Status: http.StatusServiceUnavailable})
+	if rec := serve(mux, http.MethodPatch, "/worklog/issues/TEST-1/1", `{"duration":"PT5H","comment":"Офлайн","version":1}`); rec.Code != http.StatusAccepted {
+		t.Fatalf("offline update: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	if rec := serve(mux, http.MethodPatch, "/worklog/issues/TEST-2/2", `{"duration":"PT2H","comment":"","version":1}`); rec.Code != http.StatusAccepted {
+		t.Fatalf("offline update: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	// Трекер мог создать запись до ошибки 503: в очередь она не ставится
+	if rec := serve(mux, http.MethodPost, "/worklog/issues/TEST-3", `{"date":"2025-05-07","duration":"1h"}`); rec.Code != http.StatusInternalServerError || len(worklogStore.Queue()) != 2 {
+		t.Fatalf("create after 503: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	// запрос не дошел до Трекера: создание в очереди
+	unreachable := tracker.NewTrackerClient(tracker.Config{Ctx: context.Background(), Timeout: time.Second, Client: client.New(nil), APIURL: "http://127.0.0.1:1"})
+	offline, err := NewHandler(unreachable, template.Must(template.ParseFS(web.Templates, "templates/index.html")), Config{Store: worklogStore})
+	if err != nil {
+		t.Fatal(err)
+	}
+	offlineMux := http.NewServeMux()
+	offline.SetupRoutes(offlineMux)
+	if rec := serve(offlineMux, http.MethodPost, "/worklog/issues/TEST-3", `{"date":"2025-05-07","duration":"1h"}`); rec.Code != http.StatusAccepted {
+		t.Fatalf("offline create: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	resp := get("?refresh=1")
+	if !resp.Table.Offline || len(resp.Pending) != 3 {
+		t.Errorf("offline = %v, pending = %d, want true, 3", resp.Table.Offline, len(resp.Pending))
+	}
+	if resp.Table.Sum != 9*time.Hour {
+		t.Errorf("sum with offline changes = %v, want 9h", resp.Table.Sum)
+	}
+	rec := serve(mux, http.MethodGet, "/worklog/alice/from/2025-05-01/to/2025-06-01?refresh=1", "")
+	if !strings.Contains(rec.Body.String(), "Tracker is unavailable") || !strings.Contains(rec.Body.String(), "waiting for Tracker") {
+		t.Errorf("offline page does not show banner and pending changes")
+	}
+
+	// Трекер вернулся, но запись TEST-2 успели изменить: конфликт версий
+	server.ClearFailures()
+	if _, err := server.NewTrackerClient().UpdateWorklog("TEST-2", 2, 1, time.Time{}, 3*time.Hour, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Handler{}).ReplayQueue(); err != nil {
+		t.Errorf("ReplayQueue() without store error = %v", err)
+	}
+	resp = get("?refresh=1")
+	if resp.Table.Offline || len(resp.Pending) != 1 || !strings.Contains(resp.Pending[0].Error, "version 1") {
+		t.Fatalf("after replay: offline = %v, pending = %+v", resp.Table.Offline, resp.Pending)
+	}
+	if got := len(server.Worklogs()); got != 4 {
+		t.Errorf("worklogs in tracker = %d, want 4", got)
+	}
+	if w := server.Worklogs()[0]; w.Duration != "PT5H" || w.Comment != "Офлайн" {
+		t.Errorf("replayed update = %+v", w)
+	}
+
+	rec = serve(mux, http.MethodDelete, fmt.Sprintf("/worklog/pending/%d", resp.Pending[0].ID), "")
+	if rec.Code != http.StatusNoContent || len(worklogStore.Queue()) != 0 {
+		t.Errorf("discard: status = %v, queue = %v", rec.Code, worklogStore.Queue())
+	}
+}
+
+func TestIsUnavailable(t *testing.T) {
+	dial := &url.Error{Op: "Get", URL: "https://tracker", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	timeout := &url.Error{Op: "Get", URL: "https://tracker", Err: context.DeadlineExceeded}
+	tests := []struct {
+		name        string
+		err         error
+		unavailable bool
+		stale       bool
+		// создание записи можно поставить в очередь
+		queueCreate bool
+	}{
+		{"No error", nil, false, false, false},
+		// Трекер мог создать запись до ошибки
+		{"Server error", &client.APIError{StatusCode: http.StatusServiceUnavailable}, true, true, false},
+		{"Too many requests", &client.APIError{StatusCode: http.StatusTooManyRequests}, true, true, false},
+		{"Bad request", &client.APIError{StatusCode: http.StatusBadRequest}, false, false, false},
+		{"Connection refused", fmt.Errorf("error making request: %w", dial), true, true, true},
+		{"DNS error", &url.Error{Op: "Get", URL: "https://tracker", Err: &net.DNSError{Err: "no such host", Name: "tracker", IsNotFound: true}}, true, true, true},
+		{"Timeout", fmt.Errorf("error making request: %w", timeout), false, true, false},
+		{"Connection reset", &url.Error{Op: "Get", URL: "https://tracker", Err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}}, false, false, false},
+		{"Decode error", errors.New("error decoding response"), false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUnavailable(tt.err); got != tt.unavailable {
+				t.Errorf("isUnavailable(%v) = %v, want %v", tt.err, got, tt.unavailable)
+			}
+			if got := isStale(tt.err); got != tt.stale {
+				t.Errorf("isStale(%v) = %v, want %v", tt.err, got, tt.stale)
+			}
+			if got := canQueue(store.OperationCreate, tt.err); got != tt.queueCreate {
+				t.Errorf("canQueue(create, %v) = %v, want %v", tt.err, got, tt.queueCreate)
+			}
+			if got := canQueue(store.OperationUpdate, tt.err); got != tt.unavailable {
+				t.Errorf("canQueue(update, %v) = %v, want %v", tt.err, got, tt.unavailable)
+			}
+		})
+	}
+}
+
+func TestOfflineQueueSaveError(t *testing.T) {
+	dir := t.TempDir()
+	worklogStore, err := store.Open(filepath.Join(dir, "cache", "worklogs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, mux := newTestHandler(t, Config{Store: worklogStore})
+	serve(mux, http.MethodGet, "/api/worklog/alice/from/2025-05-01/to/2025-06-01", "")
+
+	// Трекер недоступен, а очередь не сохранить: изменение не принято
+	if err := os.RemoveAll(filepath.Join(dir, "cache")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cache"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server.InjectFailure(trackertest.Failure{Status: http.StatusServiceUnavailable})
+	if rec := serve(mux, http.MethodPatch, "/worklog/issues/TEST-1/1", `{"duration":"PT5H","comment":"","version":1}`); rec.Code != http.StatusInternalServerError {
+		t.Errorf("update with unsaved queue: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	if rec := serve(mux, http.MethodDelete, "/worklog/issues/TEST-1/1", ""); rec.Code != http.StatusInternalServerError {
+		t.Errorf("delete with unsaved queue: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	if queue := worklogStore.Queue(); len(queue) != 0 {
+		t.Errorf("queue = %+v, want empty", queue)
+	}
+}
+
+func TestIssueAndQueueViews(t *testing.T) {
+	server, mux := newTestHandler(t, testConfig)
+	server.Seed(tracker.Worklog{ID: 10, Issue: tracker.Issue{Key: "TEST-1", Display: "Первая задача"}, Comment: "Помощь",
+		CreatedBy: tracker.User{Id: "bob", Display: "bob"},
+		CreatedAt: "2025-05-06T12:00:00.000+0300", Start: "2025-05-06T11:00:00.000+0300", Duration: "PT30M"})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantSum time.Duration
+	}{
+		{"Issue", "/api/worklog/issue/TEST-1/from/2025-05-01/to/2025-06-01", 6*time.Hour + 30*time.Minute},
+		{"Issue, narrow period", "/api/worklog/issue/TEST-1/from/2025-05-06/to/2025-05-07/show/from/2025-05-05/to/2025-05-08", 30 * time.Minute},
+		{"Queue", "/api/worklog/queue/TEST/from/2025-05-01/to/2025-06-01", 8 * time.Hour},
+		{"Empty queue", "/api/worklog/queue/OPS/from/2025-05-01/to/2025-06-01", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(mux, http.MethodGet, tt.path, "")
+			var resp apiWorklogResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+				t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+			}
+			if resp.Table.Sum != tt.wantSum {
+				t.Errorf("sum = %v, want %v", resp.Table.Sum, tt.wantSum)
+			}
+		})
+	}
+
+	// задачи очереди загружаются прокруткой, без предела постраничной выдачи
+	for _, r := range server.Requests() {
+		if strings.HasSuffix(r.Path, "/issues/_search") && (r.Query.Get("scrollType") == "" || r.Query.Get("fields") != "key") {
+			t.Errorf("queue search query = %v, want scroll with key field only", r.Query)
+		}
+	}
+
+	rec := serve(mux, http.MethodGet, "/worklog/issue/TEST-1/from/2025-05-01/to/2025-06-01", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "bob: Помощь") {
+		t.Errorf("issue page: status = %v, author is not shown", rec.Code)
+	}
+	rec = serve(mux, http.MethodGet, "/worklog/issue/TEST-2", "")
+	if location := rec.Header().Get("Location"); rec.Code != http.StatusFound || !strings.HasPrefix(location, "/worklog/issue/TEST-2/from/2025-05-06/to/") {
+		t.Errorf("issue all time: status = %v, location = %q", rec.Code, location)
+	}
+
+	// записи задним и будущим числом входят в период «за все время»
+	server.Seed(
+		tracker.Worklog{ID: 11, Issue: tracker.Issue{Key: "TEST-4", Display: "Четвертая задача"}, CreatedBy: tracker.User{Id: "bob", Display: "bob"},
+			CreatedAt: "2025-05-06T12:00:00.000+0000", Start: "2025-05-02T10:00:00.000+0000", Duration: "PT1H"},
+		tracker.Worklog{ID: 12, Issue: tracker.Issue{Key: "TEST-4", Display: "Четвертая задача"}, CreatedBy: tracker.User{Id: "bob", Display: "bob"},
+			CreatedAt: "2025-05-06T12:00:00.000+0000", Start: "2099-01-10T10:00:00.000+0000", Duration: "PT2H"},
+	)
+	rec = serve(mux, http.MethodGet, "/worklog/issue/TEST-4?tz=UTC", "")
+	location := rec.Header().Get("Location")
+	if want := "/worklog/issue/TEST-4/from/2025-05-02/to/2099-01-11?tz=UTC"; location != want {
+		t.Fatalf("issue all time: location = %q, want %q", location, want)
+	}
+	rec = serve(mux, http.MethodGet, "/api"+location, "")
+	var resp apiWorklogResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Table.Sum != 3*time.Hour {
+		t.Errorf("issue all time: status = %v, sum = %v, want 3h", rec.Code, resp.Table.Sum)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	server, mux := newTestHandler(t, testConfig)
+	server.SeedIssues(
+		tracker.Issue{Key: "TEST-1", Display: "Первая задача", Tags: []string{"urgent", "backend"},
+			Project: tracker.Ref{Id: "7", Display: "Платформа"}},
+		tracker.Issue{Key: "TEST-2", Display: "Вторая задача"},
+	)
+	const period = "/alice/from/2025-05-01/to/2025-06-01/show/from/2025-05-05/to/2025-05-08"
+
+	type group struct {
+		title string
+		sum   time.Duration
+	}
+	tests := []struct {
+		groupBy string
+		want    []group
+		nested  []group
+	}{
+		{"tag", []group{{"backend", 6 * time.Hour}, {"urgent", 6 * time.Hour}, {"No tag", 90 * time.Minute}}, nil},
+		{"project", []group{{"Платформа", 6 * time.Hour}, {"No project", 90 * time.Minute}}, nil},
+		{"queue,tag", []group{{"TEST", 7*time.Hour + 30*time.Minute}}, []group{{"backend", 6 * time.Hour}, {"urgent", 6 * time.Hour}, {"No tag", 90 * time.Minute}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			rec := serve(mux, http.MethodGet, "/api/worklog"+period+"?groupBy="+tt.groupBy, "")
+			var resp apiWorklogResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+				t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+			}
+			check := func(groups []Group, want []group) {
+				t.Helper()
+				var got []group
+				for _, g := range groups {
+					got = append(got, group{g.Title, g.Sum})
+				}
+				if fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Errorf("groups = %v, want %v", got, want)
+				}
+			}
+			check(resp.Table.Groups, tt.want)
+			if tt.nested != nil {
+				check(resp.Table.Groups[0].Groups, tt.nested)
+			}
+		})
+	}
+
+	rec := serve(mux, http.MethodGet, "/worklog"+period+"?groupBy=tag", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<tr class="group depth-0" data-path="2"`) {
+		t.Errorf("grouped page: status = %v, group header is not shown", rec.Code)
+	}
+	rec = serve(mux, http.MethodGet, "/worklog"+period+"?groupBy=queue&format=csv", "")
+	if !strings.Contains(rec.Body.String(), "TEST,,2 issues,4,3.5,,7.5") {
+		t.Errorf("grouped csv export: body = %s", rec.Body)
+	}
+	rec = serve(mux, http.MethodGet, "/api/worklog"+period+"?groupBy=assignee", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown groupBy: status = %v, want 400", rec.Code)
+	}
+}
+
+func TestSortOrder(t *testing.T) {
+	server, mux := newTestHandler(t, testConfig)
+	server.Seed(tracker.Worklog{ID: 11, Issue: tracker.Issue{Key: "TEST-10", Display: "Анализ"}, Comment: "Созвон",
+		CreatedBy: tracker.User{Id: "alice", Display: "alice"},
+		CreatedAt: "2025-05-05T18:00:00.000+0300", Start: "2025-05-05T09:00:00.000+0300", Duration: "PT30M"})
+
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"", "[TEST-1 TEST-2 TEST-10]"},
+		{"key", "[TEST-1 TEST-2 TEST-10]"},
+		{"total", "[TEST-1 TEST-2 TEST-10]"},
+		{"first", "[TEST-10 TEST-1 TEST-2]"},
+		{"summary", "[TEST-10 TEST-2 TEST-1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			rec := serve(mux, http.MethodGet, "/api/worklog/alice/from/2025-05-01/to/2025-06-01/show/from/2025-05-05/to/2025-05-08?sort="+tt.sort, "")
+			var resp apiWorklogResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+				t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+			}
+			var keys []string
+			for _, r := range resp.Table.Rowspans {
+				keys = append(keys, r.Issue.Key)
+			}
+			if got := fmt.Sprint(keys); got != tt.want {
+				t.Errorf("rows = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	rec := serve(mux, http.MethodGet, "/api/worklog/alice/currentMonth?sort=random", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown sort: status = %v, want 400", rec.Code)
+	}
+}
+
+func TestFilterByStart(t *testing.T) {
+	_, mux := newTestHandler(t, testConfig)
+
+	// запись 3 внесена 7 мая за работу 6 мая
+	tests := []struct {
+		name     string
+		query    string
+		wantSum  time.Duration
+		wantRows int
+	}{
+		{"By creation date", "", 90 * time.Minute, 1},
+		{"By start date", "?filterBy=start", 3*time.Hour + 30*time.Minute, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(mux, http.MethodGet, "/api/worklog/alice/from/2025-05-06/to/2025-05-07"+tt.query, "")
+			var resp apiWorklogResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+				t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+			}
+			if resp.Table.Sum != tt.wantSum || len(resp.Worklogs) != tt.wantRows {
+				t.Errorf("sum = %v, worklogs = %d, want %v, %d", resp.Table.Sum, len(resp.Worklogs), tt.wantSum, tt.wantRows)
+			}
+		})
+	}
+
+	rec := serve(mux, http.MethodGet, "/worklog/team/dev/from/2025-05-06/to/2025-05-07?filterBy=start", "")
+	if body := rec.Body.String(); rec.Code != http.StatusOK || !strings.Contains(body, "Start: 2025-05-06") || !strings.Contains(body, "/worklog/alice/from/2025-05-06/to/2025-05-07?filterBy=start") {
+		t.Errorf("team page by start: status = %v, body = %s", rec.Code, body)
+	}
+	rec = serve(mux, http.MethodGet, "/api/worklog/alice/currentMonth?filterBy=updatedAt", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown filterBy: status = %v, want 400", rec.Code)
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	newYork, _ := time.LoadLocation("America/New_York")
+	server, mux := newTestHandler(t, Config{
+		Teams:         testConfig.Teams,
+		Location:      moscow,
+		UserLocations: map[string]*time.Location{"bob": newYork},
+	})
+	// час ночи 6 мая по Москве — еще 5 мая по UTC
+	server.Seed(tracker.Worklog{ID: 20, Issue: tracker.Issue{Key: "TEST-3", Display: "Ночная задача"}, Comment: "Дежурство",
+		CreatedBy: tracker.User{Id: "alice", Display: "alice"},
+		CreatedAt: "2025-05-06T01:30:00.000+0300", Start: "2025-05-06T01:00:00.000+0300", Duration: "PT1H"})
+
+	tests := []struct {
+		name     string
+		query    string
+		wantTZ   string
+		wantDays string
+	}{
+		{"Default timezone", "", "Europe/Moscow", "[0s 1h0m0s]"},
+		{"Request timezone", "?tz=UTC", "UTC", "[1h0m0s 0s]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(mux, http.MethodGet, "/api/worklog/alice/from/2025-05-05/to/2025-05-07"+tt.query, "")
+			var resp apiWorklogResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+				t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+			}
+			i := slices.IndexFunc(resp.Table.Rowspans, func(r Rowspan) bool { return r.Issue.Key == "TEST-3" })
+			if i < 0 {
+				t.Fatalf("TEST-3 is not in the table")
+			}
+			if got := fmt.Sprint(resp.Table.Rowspans[i].DaysSum); resp.Query.Timezone != tt.wantTZ || got != tt.wantDays {
+				t.Errorf("timezone = %s, days = %s, want %s, %s", resp.Query.Timezone, got, tt.wantTZ, tt.wantDays)
+			}
+		})
+	}
+
+	rec := serve(mux, http.MethodGet, "/worklog/team/dev/from/2025-05-05/to/2025-05-07", "")
+	if body := rec.Body.String(); rec.Code != http.StatusOK || !strings.Contains(body, `<span class="timezone">America/New_York</span>`) {
+		t.Errorf("team page: status = %v, bob's timezone is not shown", rec.Code)
+	}
+	rec = serve(mux, http.MethodGet, "/api/worklog/alice/today?tz=Mars/Olympus", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown timezone: status = %v, want 400", rec.Code)
+	}
+}
+
+func TestNorms(t *testing.T) {
+	server, mux := newTestHandler(t, Config{
+		Teams: testConfig.Teams,
+		Calendar: calendar.New(4*time.Hour,
+			calendar.Day{Date: "2025-05-08", Kind: calendar.Holiday},
+			calendar.Day{Date: "2025-05-09", Kind: calendar.Shortened, Hours: 3 * time.Hour}),
+	})
+	server.Seed(tracker.Worklog{ID: 20, Issue: tracker.Issue{Key: "TEST-3", Display: "Релиз"}, Comment: "Выкладка",
+		CreatedBy: tracker.User{Id: "alice", Display: "alice"},
+		CreatedAt: "2025-05-10T18:00:00.000+0300", Start: "2025-05-10T10:00:00.000+0300", Duration: "PT1H"})
+
+	rec := serve(mux, http.MethodGet, "/api/worklog/alice/from/2025-05-05/to/2025-05-12", "")
+	var resp apiWorklogResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status = %v, body = %s", rec.Code, rec.Body)
+	}
+	// пн — норма, вт — меньше, ср — пусто, чт — праздник, пт — сокращенный, сб — запись в выходной, вс — выходной
+	want := []DayNorm{
+		{4 * time.Hour, statusOK},
+		{4 * time.Hour, statusUnder},
+		{4 * time.Hour, statusMissing},
+		{0, ""},
+		{3 * time.Hour, statusMissing},
+		{0, statusOver},
+		{0, ""},
+	}
+	if !slices.Equal(resp.Table.Norms, want) {
+		t.Errorf("norms = %v, want %v", resp.Table.Norms, want)
+	}
+	wantWeeks := []WeekNorm{{Start: "2025-05-05", Sum: 8*time.Hour + 30*time.Minute, Norm: 15 * time.Hour, Status: statusUnder}}
+	if !slices.Equal(resp.Table.Weeks, wantWeeks) {
+		t.Errorf("weeks = %v, want %v", resp.Table.Weeks, wantWeeks)
+	}
+
+	rec = serve(mux, http.MethodGet, "/worklog/alice/from/2025-05-05/to/2025-05-12", "")
+	if body := rec.Body.String(); rec.Code != http.StatusOK || !strings.Contains(body, `<th scope="col" class="day-holiday">2025-05-08</th>`) ||
+		!strings.Contains(body, `<th scope="col" class="day-weekend">2025-05-10</th>`) || !strings.Contains(body, `<th scope="col">2025-05-07</th>`) {
+		t.Errorf("worklog page: status = %v, weekends and holidays are not shaded", rec.Code)
+	}
+
+	// в отчете по задаче записи разных авторов: норма к нему не относится
+	rec = serve(mux, http.MethodGet, "/api/worklog/issue/TEST-1/from/2025-05-05/to/2025-05-12", "")
+	resp = apiWorklogResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("issue: status = %v, body = %s", rec.Code, rec.Body)
+	}
+	if resp.Table.Norms != nil || resp.Table.Weeks != nil {
+		t.Errorf("issue norms = %v, weeks = %v, want none", resp.Table.Norms, resp.Table.Weeks)
+	}
+
+	rec = serve(mux, http.MethodGet, "/worklog/team/dev/from/2025-05-05/to/2025-05-12", "")
+	if body := rec.Body.String(); rec.Code != http.StatusOK || !strings.Contains(body, `class="status-missing"`) || !strings.Contains(body, `class="status-under" title="norm 15h"`) {
+		t.Errorf("team page: status = %v, norm statuses are not highlighted", rec.Code)
+	}
+}
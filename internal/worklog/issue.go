@@ -0,0 +1,146 @@
+package worklog
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/tracker/internal/tracker"
+	"github.com/AianaM/timefns"
+)
+
+const (
+	issueParam = "issue"
+	queueParam = "queue"
+)
+
+// worklogView — чьи записи показывает отчет: автора, задачи или очереди
+type worklogView struct {
+	// часть пути между pathPrefix и периодом у отчетов задачи и очереди
+	path string
+	// название отчета для заголовка и ссылок, как createdBy у автора
+	name func(r *http.Request) string
+	load func(h *Handler, r *http.Request) loadFn
+	// в отчете записи разных авторов
+	showAuthor bool
+}
+
+var (
+	authorView = worklogView{
+		name: func(r *http.Request) string { return r.PathValue(pathParams.CreatedBy) },
+		load: func(h *Handler, r *http.Request) loadFn { return h.authorWorklogs(r.PathValue(pathParams.CreatedBy)) },
+	}
+	issueView = worklogView{
+		path:       "/issue/{" + issueParam + "}",
+		name:       func(r *http.Request) string { return "issue/" + r.PathValue(issueParam) },
+		load:       func(h *Handler, r *http.Request) loadFn { return h.issueWorklogs(r.PathValue(issueParam)) },
+		showAuthor: true,
+	}
+	queueView = worklogView{
+		path:       "/queue/{" + queueParam + "}",
+		name:       func(r *http.Request) string { return "queue/" + r.PathValue(queueParam) },
+		load:       func(h *Handler, r *http.Request) loadFn { return h.queueWorklogs(r.PathValue(queueParam)) },
+		showAuthor: true,
+	}
+)
+
+// issueWorklogs загружает записи задачи, созданные в период
+func (h *Handler) issueWorklogs(issueKey string) loadFn {
+	return func(createdAt timefns.TimeSpan, refresh bool) ([]tracker.Worklog, time.Time, error) {
+		worklogs, err := h.trackerClient.GetIssueWorklogs(issueKey)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("error getting issue worklogs: %w", err)
+		}
+		return createdIn(worklogs, createdAt), time.Time{}, nil
+	}
+}
+
+// queueWorklogs загружает записи задач очереди, созданные в период. Задачи ищутся
+// по дате изменения: добавление записи меняет затраченное время, а значит и задачу.
+func (h *Handler) queueWorklogs(queue string) loadFn {
+	return func(createdAt timefns.TimeSpan, refresh bool) ([]tracker.Worklog, time.Time, error) {
+		issues, err := h.trackerClient.SearchIssues(tracker.IssueSearch{
+			Query: fmt.Sprintf("Queue: %q Updated: >= %q", queue, createdAt.Start.Format(time.DateOnly)),
+			// нужны только ключи; в большой очереди задач больше 10000 — предела постраничной выдачи
+			Fields: []string{"key"},
+			Scroll: true,
+		})
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("error searching queue issues: %w", err)
+		}
+
+		results := make([][]tracker.Worklog, len(issues))
+		errs := make([]error, len(issues))
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, teamConcurrency)
+		for i, issue := range issues {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				results[i], errs[i] = h.trackerClient.GetIssueWorklogs(issue.Key)
+			}()
+		}
+		wg.Wait()
+
+		var worklogs []tracker.Worklog
+		for i := range issues {
+			if errs[i] != nil {
+				return nil, time.Time{}, fmt.Errorf("error getting %s worklogs: %w", issues[i].Key, errs[i])
+			}
+			worklogs = append(worklogs, createdIn(results[i], createdAt)...)
+		}
+		return worklogs, time.Time{}, nil
+	}
+}
+
+// createdIn оставляет записи, созданные в период, как фильтр createdAt в Трекере
+func createdIn(worklogs []tracker.Worklog, createdAt timefns.TimeSpan) []tracker.Worklog {
+	return slices.DeleteFunc(slices.Clone(worklogs), func(w tracker.Worklog) bool {
+		created, err := timefns.Parse(w.CreatedAt)
+		return err != nil || created.Before(createdAt.Start) || created.After(createdAt.End)
+	})
+}
+
+// issueAllTimeHandler показывает все записи задачи: перенаправляет на период
+// от самой ранней даты создания или начала работы до дня после самой поздней,
+// но не раньше завтрашнего. Таблица раскладывает записи по началу работы,
+// поэтому записи задним или будущим числом тоже должны попасть в период.
+func (h *Handler) issueAllTimeHandler(w http.ResponseWriter, r *http.Request) {
+	issueKey := r.PathValue(issueParam)
+	loc, err := h.location(r, "")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing timezone: %v", err), http.StatusBadRequest)
+		return
+	}
+	worklogs, err := h.trackerClient.GetIssueWorklogs(issueKey)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting issue worklogs: %v", err), errorStatus(err))
+		return
+	}
+	from, last := time.Now().In(loc), time.Now().In(loc)
+	for _, worklog := range worklogs {
+		for _, value := range []string{worklog.CreatedAt, worklog.Start} {
+			t, err := timefns.Parse(value)
+			if err != nil {
+				continue
+			}
+			if t.Before(from) {
+				from = t.In(loc)
+			}
+			if t.After(last) {
+				last = t.In(loc)
+			}
+		}
+	}
+	to := last.AddDate(0, 0, 1)
+	target := strings.Join([]string{pathPrefix, "issue", issueKey, "from", from.Format(time.DateOnly), "to", to.Format(time.DateOnly)}, "/")
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
@@ -0,0 +1,128 @@
+package worklog
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"example.com/tracker/internal/calendar"
+)
+
+// Norms — нормы рабочего времени; норма дня берется из производственного календаря
+type Norms struct {
+	// норма недели; 0 — сумма дневных норм. Если задана, делится между
+	// днями недели пропорционально их дневным нормам.
+	Weekly time.Duration
+	// отклонение от нормы, в пределах которого день считается в норме
+	Tolerance time.Duration
+}
+
+// DayStatus — сколько списано за день или неделю относительно нормы
+type DayStatus string
+
+const (
+	// рабочий день без записей
+	statusMissing DayStatus = "missing"
+	// списано меньше нормы
+	statusUnder DayStatus = "under"
+	statusOK    DayStatus = "ok"
+	// списано больше нормы, в том числе в выходной
+	statusOver DayStatus = "over"
+)
+
+// DayNorm — норма дня и статус; у выходного без записей и у дней
+// после сегодняшнего статус пустой
+type DayNorm struct {
+	Norm   time.Duration `json:"norm"`
+	Status DayStatus     `json:"status,omitempty"`
+}
+
+// WeekNorm — итог недели: норма считается по прошедшим дням недели в периоде
+type WeekNorm struct {
+	// понедельник недели или первый день периода
+	Start  string        `json:"start"`
+	Sum    time.Duration `json:"sum"`
+	Norm   time.Duration `json:"norm"`
+	Status DayStatus     `json:"status,omitempty"`
+}
+
+// status сравнивает списанное время с нормой
+func (n Norms) status(sum, norm time.Duration) DayStatus {
+	switch {
+	case sum == 0 && norm == 0:
+		return ""
+	case sum == 0:
+		return statusMissing
+	case sum < norm-n.Tolerance:
+		return statusUnder
+	case sum > norm+n.Tolerance:
+		return statusOver
+	}
+	return statusOK
+}
+
+// check считает нормы и статусы дней таблицы по календарю cal и итоги по неделям.
+// Дни после now не оцениваются: за них еще рано требовать записи.
+func (n Norms) check(cal *calendar.Calendar, days []string, daysSum []time.Duration, loc *time.Location, now time.Time) ([]DayNorm, []WeekNorm) {
+	today := now.In(loc).Format(time.DateOnly)
+	// норма полной недели, от которой считается доля дня при заданной Weekly
+	regularWeek := 5 * cal.Hours()
+	norms := make([]DayNorm, len(days))
+	var weeks []WeekNorm
+	for i, date := range days {
+		day, err := time.ParseInLocation(time.DateOnly, date, loc)
+		if err != nil {
+			continue
+		}
+		norms[i].Norm = cal.WorkingHours(day)
+		if len(weeks) == 0 || day.Weekday() == time.Monday {
+			weeks = append(weeks, WeekNorm{Start: date})
+		}
+		if date > today {
+			continue
+		}
+		norms[i].Status = n.status(daysSum[i], norms[i].Norm)
+		week := &weeks[len(weeks)-1]
+		week.Sum += daysSum[i]
+		if n.Weekly > 0 {
+			week.Norm += time.Duration(float64(n.Weekly) * float64(norms[i].Norm) / float64(regularWeek))
+		} else {
+			week.Norm += norms[i].Norm
+		}
+	}
+	for i := range weeks {
+		if weeks[i].Start <= today {
+			weeks[i].Status = n.status(weeks[i].Sum, weeks[i].Norm)
+		}
+	}
+	return norms, weeks
+}
+
+// UserCalendar — календарь участника: первой по названию команды со своим
+// календарем, иначе общий
+func (h *Handler) UserCalendar(login string) *calendar.Calendar {
+	for _, team := range slices.Sorted(maps.Keys(h.config.TeamCalendars)) {
+		if slices.Contains(h.config.Teams[team], login) {
+			return h.config.TeamCalendars[team]
+		}
+	}
+	return h.config.Calendar
+}
+
+func (h *Handler) teamCalendar(team string) *calendar.Calendar {
+	if cal, ok := h.config.TeamCalendars[team]; ok {
+		return cal
+	}
+	return h.config.Calendar
+}
+
+// dayKinds — виды дней таблицы: выходные и праздники выделяются в шапке
+func dayKinds(cal *calendar.Calendar, days []string, loc *time.Location) []calendar.Kind {
+	kinds := make([]calendar.Kind, len(days))
+	for i, date := range days {
+		if day, err := time.ParseInLocation(time.DateOnly, date, loc); err == nil {
+			kinds[i] = cal.Kind(day)
+		}
+	}
+	return kinds
+}
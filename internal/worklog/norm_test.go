@@ -0,0 +1,87 @@
+package worklog
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"example.com/tracker/internal/calendar"
+)
+
+func TestNormsCheck(t *testing.T) {
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	// неделя с понедельника 2025-05-12 по вторник следующей недели
+	days := showDays(time.Date(2025, 5, 12, 0, 0, 0, 0, moscow), time.Date(2025, 5, 21, 0, 0, 0, 0, moscow))
+	daysSum := make([]time.Duration, len(days))
+	daysSum[0] = 8 * time.Hour
+	daysSum[1] = 7*time.Hour + 50*time.Minute
+	daysSum[2] = 9 * time.Hour
+	now := time.Date(2025, 5, 13, 12, 0, 0, 0, moscow)
+
+	tests := []struct {
+		name      string
+		norms     Norms
+		wantDays  []DayStatus
+		wantWeeks []WeekNorm
+	}{
+		{
+			name:     "Days after now are not checked",
+			norms:    Norms{},
+			wantDays: []DayStatus{statusOK, statusUnder, "", "", "", "", "", "", ""},
+			wantWeeks: []WeekNorm{
+				{Start: "2025-05-12", Sum: 15*time.Hour + 50*time.Minute, Norm: 16 * time.Hour, Status: statusUnder},
+				{Start: "2025-05-19"},
+			},
+		},
+		{
+			name:     "Tolerance and weekly norm",
+			norms:    Norms{Weekly: 20 * time.Hour, Tolerance: 15 * time.Minute},
+			wantDays: []DayStatus{statusOK, statusOK, "", "", "", "", "", "", ""},
+			wantWeeks: []WeekNorm{
+				{Start: "2025-05-12", Sum: 15*time.Hour + 50*time.Minute, Norm: 8 * time.Hour, Status: statusOver},
+				{Start: "2025-05-19"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			norms, weeks := tt.norms.check(calendar.New(8*time.Hour), days, daysSum, moscow, now)
+			var statuses []DayStatus
+			for _, n := range norms {
+				statuses = append(statuses, n.Status)
+			}
+			if !slices.Equal(statuses, tt.wantDays) {
+				t.Errorf("statuses = %v, want %v", statuses, tt.wantDays)
+			}
+			if !slices.Equal(weeks, tt.wantWeeks) {
+				t.Errorf("weeks = %v, want %v", weeks, tt.wantWeeks)
+			}
+		})
+	}
+}
+
+func TestUserCalendar(t *testing.T) {
+	base := calendar.New(8 * time.Hour)
+	minsk := base.With(calendar.Day{Date: "2025-07-03", Kind: calendar.Holiday})
+	h := &Handler{config: Config{
+		Teams:         map[string][]string{"backend": {"alice", "bob"}, "minsk": {"bob"}},
+		Calendar:      base,
+		TeamCalendars: map[string]*calendar.Calendar{"minsk": minsk},
+	}}
+
+	tests := []struct {
+		name string
+		got  *calendar.Calendar
+		want *calendar.Calendar
+	}{
+		{"Member of a team with its own calendar", h.UserCalendar("bob"), minsk},
+		{"Other users get the common calendar", h.UserCalendar("alice"), base},
+		{"Team calendar", h.teamCalendar("minsk"), minsk},
+		{"Team without its own calendar", h.teamCalendar("backend"), base},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got another calendar", tt.name)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package worklog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"example.com/tracker/internal/client"
+	"example.com/tracker/internal/store"
+	"example.com/tracker/internal/tracker"
+)
+
+const operationIdParam = "operationId"
+
+// isUnavailable — Трекер недоступен: запрос не дошел до него или Трекер
+// ответил ошибкой на своей стороне (5xx, 429). Ошибки в самом запросе (4xx),
+// ошибки разбора ответа и таймауты так не считаются.
+func isUnavailable(err error) bool {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	return notSent(err)
+}
+
+// notSent — запрос не дошел до Трекера: не удалось найти адрес или соединиться
+func notSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// canQueue — изменение можно отправить позже. Создание записи, получившее
+// 5xx, Трекер мог уже применить, и повтор дал бы дубликат, поэтому оно
+// откладывается, только если запрос не дошел до Трекера — как и клиент
+// не повторяет POST (см. client.RetryConfig). Изменение и удаление при
+// повторе не дублируются: их защищают версия записи и ответ 404.
+func canQueue(kind store.OperationKind, err error) bool {
+	if kind == store.OperationCreate {
+		return notSent(err)
+	}
+	return isUnavailable(err)
+}
+
+// isStale — при чтении можно показать данные из кэша: Трекер недоступен
+// или не ответил вовремя
+func isStale(err error) bool {
+	var netErr net.Error
+	return isUnavailable(err) || errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// enqueue ставит изменение в очередь, если Трекер недоступен и кэш настроен.
+// queued == false — изменение не в очереди, обрабатывается ошибка Трекера;
+// err — очередь не удалось сохранить.
+func (h *Handler) enqueue(trackerErr error, op store.Operation) (_ store.Operation, queued bool, err error) {
+	if h.config.Store == nil || !canQueue(op.Kind, trackerErr) {
+		return store.Operation{}, false, nil
+	}
+	op, err = h.config.Store.Enqueue(op)
+	if err != nil {
+		return store.Operation{}, false, fmt.Errorf("error saving offline operation: %w", err)
+	}
+	return op, true, nil
+}
+
+// pendingOperations возвращает изменения, ожидающие отправки в Трекер
+func (h *Handler) pendingOperations() []store.Operation {
+	if h.config.Store == nil {
+		return nil
+	}
+	return h.config.Store.Queue()
+}
+
+// ReplayQueue отправляет в Трекер изменения, сделанные без связи с ним.
+// Изменения, которые Трекер отклонил (например, запись успели изменить —
+// ее версия уже другая), остаются в очереди с причиной до отмены пользователем.
+// Если Трекер все еще недоступен, отправка прекращается до следующей попытки.
+// Создание записи, на которое Трекер ответил ошибкой, не повторяется.
+func (h *Handler) ReplayQueue() error {
+	if h.config.Store == nil {
+		return nil
+	}
+	h.replayMu.Lock()
+	defer h.replayMu.Unlock()
+
+	for _, op := range h.config.Store.Queue() {
+		if op.Error != "" {
+			continue
+		}
+		worklog, err := h.replay(op)
+		if canQueue(op.Kind, err) {
+			return fmt.Errorf("error replaying offline operation %d: %w", op.ID, err)
+		}
+		if errors.Is(err, tracker.ErrVersionConflict) {
+			err = fmt.Errorf("worklog was changed in Tracker after version %d, the change was not applied", op.Version)
+		}
+		if err != nil {
+			if err := h.config.Store.Fail(op.ID, err.Error()); err != nil {
+				log.Println("Error saving offline operation:", err)
+			}
+			continue
+		}
+		if worklog.ID != 0 {
+			h.cachePut(worklog)
+		}
+		if err := h.config.Store.Done(op.ID); err != nil {
+			log.Println("Error saving offline operation:", err)
+		}
+	}
+	return nil
+}
+
+func (h *Handler) replay(op store.Operation) (tracker.Worklog, error) {
+	switch op.Kind {
+	case store.OperationCreate:
+		return h.trackerClient.CreateWorklog(op.IssueKey, op.Start, op.Duration, op.Comment)
+	case store.OperationUpdate:
+		return h.trackerClient.UpdateWorklog(op.IssueKey, op.WorklogID, op.Version, time.Time{}, op.Duration, op.Comment)
+	case store.OperationDelete:
+		err := h.trackerClient.DeleteWorklog(op.IssueKey, op.WorklogID)
+		if errorStatus(err) == http.StatusNotFound {
+			// запись уже удалена
+			err = nil
+		}
+		return tracker.Worklog{}, err
+	default:
+		return tracker.Worklog{}, fmt.Errorf("unknown operation: %s", op.Kind)
+	}
+}
+
+// RunReplay периодически отправляет очередь, пока не отменен ctx
+func (h *Handler) RunReplay(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := h.ReplayQueue(); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+// discardOperationHandler отменяет изменение из очереди
+func (h *Handler) discardOperationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue(operationIdParam))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing operation id: %v", err))
+		return
+	}
+	if h.config.Store == nil {
+		writeJSONError(w, http.StatusNotFound, "Offline queue is not configured")
+		return
+	}
+	if err := h.config.Store.Done(id); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error discarding operation: %v", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,146 @@
+package worklog
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/AianaM/timefns"
+)
+
+type Preset string
+
+// presetDef — именованный период; из реестра presets разбираются пути
+// отчетов и строятся ссылки в шапке
+type presetDef struct {
+	name  string
+	title string
+	span  func(now time.Time) timefns.TimeSpan
+}
+
+// rollingPreset — последние N дней или недель, включая сегодня: last7days, last4weeks
+type rollingPreset struct {
+	unit  string
+	days  int
+	title string
+	// N, для которых показываются ссылки в шапке
+	header []int
+}
+
+// maxRollingDays ограничивает last{N}days и last{N}weeks, чтобы опечатка
+// в адресе не загружала записи за десятилетия
+const maxRollingDays = 1000
+
+var (
+	presets = []presetDef{
+		{"today", "Сегодня", func(now time.Time) timefns.TimeSpan {
+			today := startOfDay(now)
+			return timefns.TimeSpan{Start: today, End: today.AddDate(0, 0, 1)}
+		}},
+		{"yesterday", "Вчера", func(now time.Time) timefns.TimeSpan {
+			today := startOfDay(now)
+			return timefns.TimeSpan{Start: today.AddDate(0, 0, -1), End: today}
+		}},
+		{"currentWeek", "Текущая неделя", func(now time.Time) timefns.TimeSpan {
+			week := weekStart(now)
+			return timefns.TimeSpan{Start: week, End: week.AddDate(0, 0, 7)}
+		}},
+		{"lastWeek", "Прошлая неделя", func(now time.Time) timefns.TimeSpan {
+			week := weekStart(now)
+			return timefns.TimeSpan{Start: week.AddDate(0, 0, -7), End: week}
+		}},
+		{"currentMonth", "Текущий месяц", func(now time.Time) timefns.TimeSpan {
+			month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+			return timefns.TimeSpan{Start: month, End: month.AddDate(0, 1, 0)}
+		}},
+		{"lastMonth", "Прошлый месяц", func(now time.Time) timefns.TimeSpan {
+			month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+			return timefns.TimeSpan{Start: month.AddDate(0, -1, 0), End: month}
+		}},
+		{"currentQuarter", "Текущий квартал", func(now time.Time) timefns.TimeSpan {
+			quarter := quarterStart(now)
+			return timefns.TimeSpan{Start: quarter, End: quarter.AddDate(0, 3, 0)}
+		}},
+		{"lastQuarter", "Прошлый квартал", func(now time.Time) timefns.TimeSpan {
+			quarter := quarterStart(now)
+			return timefns.TimeSpan{Start: quarter.AddDate(0, -3, 0), End: quarter}
+		}},
+		{"currentYear", "Текущий год", func(now time.Time) timefns.TimeSpan {
+			year := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+			return timefns.TimeSpan{Start: year, End: year.AddDate(1, 0, 0)}
+		}},
+	}
+	rollingPresets = []rollingPreset{
+		{unit: "days", days: 1, title: "Последние %d дн.", header: []int{7, 30}},
+		{unit: "weeks", days: 7, title: "Последние %d нед.", header: []int{4}},
+	}
+	rollingPresetRe = regexp.MustCompile(`^last(\d+)(days|weeks)$`)
+)
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// weekStart — понедельник недели t
+func weekStart(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+func quarterStart(t time.Time) time.Time {
+	return time.Date(t.Year(), (t.Month()-1)/3*3+1, 1, 0, 0, 0, 0, t.Location())
+}
+
+// timespan считает период пресета относительно now в его часовом поясе
+func (p Preset) timespan(now time.Time) (titledTimeSpan[time.Time], error) {
+	if i := slices.IndexFunc(presets, func(d presetDef) bool { return d.name == string(p) }); i >= 0 {
+		return titledTimeSpan[time.Time]{presets[i].title, presets[i].span(now)}, nil
+	}
+	if m := rollingPresetRe.FindStringSubmatch(string(p)); m != nil {
+		i := slices.IndexFunc(rollingPresets, func(r rollingPreset) bool { return r.unit == m[2] })
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || n > maxRollingDays/rollingPresets[i].days {
+			return titledTimeSpan[time.Time]{}, fmt.Errorf("preset %s must cover 1 to %d days", p, maxRollingDays)
+		}
+		end := startOfDay(now).AddDate(0, 0, 1)
+		return titledTimeSpan[time.Time]{
+			fmt.Sprintf(rollingPresets[i].title, n),
+			timefns.TimeSpan{Start: end.AddDate(0, 0, -n*rollingPresets[i].days), End: end},
+		}, nil
+	}
+	return titledTimeSpan[time.Time]{}, fmt.Errorf("unknown preset: %s", p)
+}
+
+// PresetNames — имена пресетов для справки: именованные периоды
+// и шаблоны last{N}days, last{N}weeks
+func PresetNames() []string {
+	var names []string
+	for _, d := range presets {
+		names = append(names, d.name)
+	}
+	for _, r := range rollingPresets {
+		names = append(names, "last{N}"+r.unit)
+	}
+	return names
+}
+
+// headerPreset — ссылка на период в шапке страницы
+type headerPreset struct {
+	Name  string
+	Title string
+}
+
+func headerPresets() []headerPreset {
+	var links []headerPreset
+	for _, d := range presets {
+		links = append(links, headerPreset{d.name, d.title})
+	}
+	for _, r := range rollingPresets {
+		for _, n := range r.header {
+			links = append(links, headerPreset{fmt.Sprintf("last%d%s", n, r.unit), fmt.Sprintf(r.title, n)})
+		}
+	}
+	return links
+}
@@ -0,0 +1,65 @@
+package worklog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPresetTimespan(t *testing.T) {
+	// среда
+	now := time.Date(2025, 5, 7, 15, 30, 0, 0, time.Local)
+	date := func(month time.Month, day int) string {
+		return time.Date(2025, month, day, 0, 0, 0, 0, time.Local).Format(time.DateOnly)
+	}
+
+	tests := []struct {
+		preset    Preset
+		wantTitle string
+		wantStart string
+		wantEnd   string
+	}{
+		{"yesterday", "Вчера", date(5, 6), date(5, 7)},
+		{"lastWeek", "Прошлая неделя", date(4, 28), date(5, 5)},
+		{"lastMonth", "Прошлый месяц", date(4, 1), date(5, 1)},
+		{"currentQuarter", "Текущий квартал", date(4, 1), date(7, 1)},
+		{"lastQuarter", "Прошлый квартал", date(1, 1), date(4, 1)},
+		{"currentYear", "Текущий год", date(1, 1), "2026-01-01"},
+		{"last7days", "Последние 7 дн.", date(5, 1), date(5, 8)},
+		{"last2weeks", "Последние 2 нед.", date(4, 24), date(5, 8)},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.preset), func(t *testing.T) {
+			got, err := tt.preset.timespan(now)
+			if err != nil {
+				t.Fatalf("timespan() error = %v", err)
+			}
+			start, end := got.Timespan.Start.Format(time.DateOnly), got.Timespan.End.Format(time.DateOnly)
+			if got.Title != tt.wantTitle || start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("timespan() = %s %s - %s, want %s %s - %s", got.Title, start, end, tt.wantTitle, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+
+	for _, preset := range []Preset{"lastYear", "last0days", "last200weeks", "last2000000000000000000weeks", "lastdays"} {
+		if _, err := preset.timespan(now); err == nil {
+			t.Errorf("timespan(%s) error = nil", preset)
+		}
+	}
+
+	// справка перечисляет все пресеты реестра
+	for _, name := range PresetNames() {
+		if _, err := Preset(strings.Replace(name, "{N}", "2", 1)).timespan(now); err != nil {
+			t.Errorf("PresetNames() has %s: timespan() error = %v", name, err)
+		}
+	}
+}
+
+func TestHeaderPresets(t *testing.T) {
+	for _, link := range headerPresets() {
+		got, err := Preset(link.Name).timespan(time.Now())
+		if err != nil || got.Title != link.Title {
+			t.Errorf("header preset %s: title = %q, error = %v", link.Name, got.Title, err)
+		}
+	}
+}
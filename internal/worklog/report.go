@@ -0,0 +1,100 @@
+package worklog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"example.com/tracker/internal/humanize"
+	"example.com/tracker/internal/tracker"
+)
+
+const reportSummaryWidth = 40
+
+// ReportParams описывает период отчета: пресет или даты from/to в формате 2006-01-02
+type ReportParams struct {
+	CreatedBy string
+	Preset    string
+	From      string
+	To        string
+	// период относится к началу работы, а не к созданию записей
+	ByStart bool
+	// часовой пояс, в котором считаются дни; nil — time.Local
+	Location *time.Location
+}
+
+// WriteReport загружает записи пользователя за период и печатает их
+// текстовой таблицей: задачи по строкам, дни по столбцам
+func WriteReport(out io.Writer, trackerClient *tracker.TrackerClient, p ReportParams) error {
+	q, err := worklogQuery(PathParams{
+		CreatedBy: p.CreatedBy,
+		Worklog:   timespanParams{Preset: p.Preset, From: p.From, To: p.To},
+		Location:  p.Location,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating report query: %w", err)
+	}
+	getWorklog := trackerClient.GetWorklog
+	if p.ByStart {
+		getWorklog = trackerClient.GetWorklogByStart
+	}
+	worklogs, err := getWorklog(q.CreatedBy, q.CreatedAt.Timespan)
+	if err != nil {
+		return fmt.Errorf("error getting worklogs: %w", err)
+	}
+	table, err := Worklogs(worklogs).asTable(q.Show.Timespan, sortByKey)
+	if err != nil {
+		return fmt.Errorf("error creating table: %w", err)
+	}
+
+	fmt.Fprintf(out, "%s: %s, %s - %s\n\n", q.CreatedBy, q.CreatedAt.Title,
+		q.CreatedAt.Timespan.Start.Format(time.DateOnly), q.CreatedAt.Timespan.End.Format(time.DateOnly))
+	return table.writeText(out)
+}
+
+func (t TableData) writeText(out io.Writer) error {
+	if len(t.Rowspans) == 0 {
+		_, err := fmt.Fprintln(out, "No data")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	header := []string{"issue", "summary"}
+	for _, day := range t.Days {
+		header = append(header, day[len("2006-"):])
+	}
+	fmt.Fprintln(tw, strings.Join(append(header, "sum"), "\t"))
+
+	for _, rowspan := range t.Rowspans {
+		row := []string{rowspan.Issue.Key, truncate(rowspan.Issue.Display, reportSummaryWidth)}
+		for _, d := range rowspan.DaysSum {
+			row = append(row, durationCell(d))
+		}
+		fmt.Fprintln(tw, strings.Join(append(row, humanize.Duration(rowspan.Sum)), "\t"))
+	}
+
+	total := []string{"total", ""}
+	for _, d := range t.DaysSum {
+		total = append(total, durationCell(d))
+	}
+	fmt.Fprintln(tw, strings.Join(append(total, humanize.Duration(t.Sum)), "\t"))
+
+	return tw.Flush()
+}
+
+func durationCell(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return humanize.Duration(d)
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
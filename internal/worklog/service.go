@@ -1,43 +1,72 @@
 package worklog
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"slices"
 	"time"
 
+	"example.com/tracker/internal/calendar"
 	"example.com/tracker/internal/tracker"
 	"github.com/AianaM/durationiso8601"
 	"github.com/AianaM/timefns"
 )
 
+type Row struct {
+	ID        int           `json:"id"`
+	Version   int           `json:"version"`
+	CreatedBy string        `json:"createdBy"`
+	Comment   string        `json:"comment"`
+	Duration  []string      `json:"duration"`
+	Sum       time.Duration `json:"sum"`
+}
 type Rowspan struct {
-	Issue   tracker.Issue
-	Rowspan int
-	Rows    []struct {
-		Comment  string
-		Duration []string
-	}
-	Sum time.Duration
+	Issue   tracker.Issue   `json:"issue"`
+	Rowspan int             `json:"rowspan"`
+	Rows    []Row           `json:"rows"`
+	DaysSum []time.Duration `json:"daysSum"`
+	Sum     time.Duration   `json:"sum"`
+	// начало самой ранней записи задачи в показываемом периоде
+	FirstActivity time.Time `json:"firstActivity"`
 }
 type TableData struct {
-	Days     []string
-	Rowspans map[string]Rowspan
-	DaysSum  []time.Duration
-	Sum      time.Duration
+	Days []string `json:"days"`
+	// задачи в порядке вывода, см. sortOrder
+	Rowspans []Rowspan       `json:"rowspans"`
+	DaysSum  []time.Duration `json:"daysSum"`
+	Sum      time.Duration   `json:"sum"`
+	// время синхронизации кэша; нулевое — записи загружены из Трекера сейчас
+	SyncedAt time.Time `json:"syncedAt,omitzero"`
+	// Трекер недоступен, показаны записи на момент SyncedAt
+	Offline bool `json:"offline,omitempty"`
+	// по какой дате записи отобраны в период загрузки; пусто — по дате создания
+	FilterBy filterBy `json:"filterBy,omitempty"`
+	// поля группировки и группы задач; пусто — таблица без группировки
+	GroupBy []string `json:"groupBy,omitempty"`
+	Groups  []Group  `json:"groups,omitempty"`
+	// виды дней по производственному календарю: working, weekend, holiday, shortened
+	DayKinds []calendar.Kind `json:"dayKinds,omitempty"`
+	// нормы и статусы дней и итоги по неделям; пусто, если нормы не
+	// настроены или в отчете записи разных авторов
+	Norms []DayNorm  `json:"norms,omitempty"`
+	Weeks []WeekNorm `json:"weeks,omitempty"`
 }
 type Worklogs []tracker.Worklog
 
-func (w Worklogs) asTable(show timefns.TimeSpan) (TableData, error) {
-	days := []string{}
-	for i := show.Start; i.Before(show.End); i = i.AddDate(0, 0, 1) {
-		days = append(days, i.Format(time.DateOnly))
-	}
+// asTable раскладывает записи по дням периода show; день записи считается
+// в часовом поясе периода, а не в том, что пришел из Трекера
+func (w Worklogs) asTable(show timefns.TimeSpan, order sortOrder) (TableData, error) {
+	days := showDays(show.Start, show.End)
 	daysLen := len(days)
 	if daysLen == 0 {
 		return TableData{}, nil
 	}
 	daysSums := make([]time.Duration, daysLen)
-	rowspans := map[string]Rowspan{}
+	rowspans := []Rowspan{}
+	// индекс задачи в rowspans по ключу
+	index := map[string]int{}
 	sum := time.Duration(0)
 
 	for _, w := range w {
@@ -45,47 +74,166 @@ func (w Worklogs) asTable(show timefns.TimeSpan) (TableData, error) {
 		if err != nil {
 			return TableData{}, fmt.Errorf("error parsing date: %w", err)
 		}
-		dateStr := date.Format(time.DateOnly)
+		dateStr := date.In(show.Start.Location()).Format(time.DateOnly)
 		for i, v := range days {
 			if dateStr != v {
 				continue
 			}
-			if _, ok := rowspans[w.Issue.Key]; !ok {
-				rowspans[w.Issue.Key] = Rowspan{w.Issue, 0, []struct {
-					Comment  string
-					Duration []string
-				}{}, time.Duration(0)}
+			if _, ok := index[w.Issue.Key]; !ok {
+				index[w.Issue.Key] = len(rowspans)
+				rowspans = append(rowspans, Rowspan{Issue: w.Issue, Rows: []Row{}, DaysSum: make([]time.Duration, daysLen), FirstActivity: date})
 			}
 			newRow := make([]string, daysLen)
 			newRow[i] = w.Duration
-			rowspan := rowspans[w.Issue.Key]
+			rowspan := &rowspans[index[w.Issue.Key]]
 			rowspan.Rowspan++
-			rowspan.Rows = append(rowspan.Rows, struct {
-				Comment  string
-				Duration []string
-			}{w.Comment, newRow})
-			if date, err := timefns.Parse(w.Start); err != nil {
-				log.Println("Error parsing date:", err)
-			} else if duration, err := durationiso8601.ParseDuration(date, w.Duration); err != nil {
+			if date.Before(rowspan.FirstActivity) {
+				rowspan.FirstActivity = date
+			}
+			row := Row{w.ID, w.Version, w.CreatedBy.Display, w.Comment, newRow, 0}
+			if duration, err := durationiso8601.ParseDuration(date, w.Duration); err != nil {
 				log.Println("Error parsing duration:", err)
 			} else {
+				row.Sum = duration
 				rowspan.Sum += duration
+				rowspan.DaysSum[i] += duration
 				daysSums[i] += duration
 				sum += duration
 			}
 
-			rowspans[w.Issue.Key] = rowspan
+			rowspan.Rows = append(rowspan.Rows, row)
 			break
 		}
 	}
+	sortRowspans(rowspans, order)
 
-	return TableData{days, rowspans, daysSums, sum}, nil
+	return TableData{Days: days, Rowspans: rowspans, DaysSum: daysSums, Sum: sum}, nil
 }
 
-func (h *Handler) getWorklogsTable(createdBy string, timespan, show titledTimeSpan[time.Time]) (TableData, error) {
-	worklogs, err := h.trackerClient.GetWorklog(createdBy, timespan.Timespan)
+// showDays возвращает дни периода [start, end) в формате 2006-01-02
+func showDays(start, end time.Time) []string {
+	days := []string{}
+	for i := start; i.Before(end); i = i.AddDate(0, 0, 1) {
+		days = append(days, i.Format(time.DateOnly))
+	}
+	return days
+}
+
+// rowspan возвращает задачу таблицы по ключу
+func (t TableData) rowspan(key string) *Rowspan {
+	i := slices.IndexFunc(t.Rowspans, func(r Rowspan) bool { return r.Issue.Key == key })
+	if i < 0 {
+		return nil
+	}
+	return &t.Rowspans[i]
+}
+
+// issueKeys возвращает ключи задач таблицы в порядке вывода
+func (t TableData) issueKeys() []string {
+	keys := make([]string, len(t.Rowspans))
+	for i, r := range t.Rowspans {
+		keys[i] = r.Issue.Key
+	}
+	return keys
+}
+
+// loadFn загружает записи отчета, созданные в период createdAt,
+// и возвращает время синхронизации, если записи взяты из кэша
+type loadFn func(createdAt timefns.TimeSpan, refresh bool) ([]tracker.Worklog, time.Time, error)
+
+// authorWorklogs загружает записи автора
+func (h *Handler) authorWorklogs(createdBy string) loadFn {
+	return func(createdAt timefns.TimeSpan, refresh bool) ([]tracker.Worklog, time.Time, error) {
+		return h.getWorklogs(createdBy, createdAt, refresh)
+	}
+}
+
+const filterParam = "filterBy"
+
+// filterBy — по какой дате записи попадают в период загрузки: когда время
+// внесли (createdAt, как фильтрует Трекер) или когда шла работа (start).
+// Окно поиска по началу работы — tracker.StartWindow.
+type filterBy string
+
+const (
+	filterByCreatedAt filterBy = "createdAt"
+	filterByStart     filterBy = "start"
+)
+
+func parseFilterBy(value string) (filterBy, error) {
+	switch filterBy(value) {
+	case "", filterByCreatedAt:
+		return filterByCreatedAt, nil
+	case filterByStart:
+		return filterByStart, nil
+	default:
+		return "", fmt.Errorf("unknown filterBy: %q", value)
+	}
+}
+
+// tableOptions — параметры таблицы из строки запроса
+type tableOptions struct {
+	refresh  bool
+	groupBy  []groupField
+	sort     sortOrder
+	filterBy filterBy
+	// сравнивать дни с нормой: отчет по одному автору
+	norms bool
+	// календарь автора или команды; nil — без норм и выделения дней
+	calendar *calendar.Calendar
+}
+
+func parseTableOptions(r *http.Request) (tableOptions, error) {
+	groupBy, err := parseGroupBy(r.URL.Query().Get(groupByParam))
+	if err != nil {
+		return tableOptions{}, err
+	}
+	order, err := parseSortOrder(r.URL.Query().Get(sortParam))
+	if err != nil {
+		return tableOptions{}, err
+	}
+	filter, err := parseFilterBy(r.URL.Query().Get(filterParam))
 	if err != nil {
-		return TableData{}, fmt.Errorf("error getting worklogs: %w", err)
+		return tableOptions{}, err
+	}
+	return tableOptions{refresh: refreshRequested(r), groupBy: groupBy, sort: order, filterBy: filter}, nil
+}
+
+func (h *Handler) getWorklogsTable(load loadFn, timespan, show titledTimeSpan[time.Time], opts tableOptions) (Worklogs, TableData, error) {
+	// Трекер ищет только по дате создания: записи по началу работы
+	// загружаются за расширенный период и отбираются здесь
+	createdAt := timespan.Timespan
+	if opts.filterBy == filterByStart {
+		createdAt = tracker.StartWindow(timespan.Timespan)
+	}
+	worklogs, syncedAt, err := load(createdAt, opts.refresh)
+	offline := errors.Is(err, errOffline)
+	if offline {
+		log.Println(err)
+	} else if err != nil {
+		return nil, TableData{}, fmt.Errorf("error getting worklogs: %w", err)
+	}
+	if opts.filterBy == filterByStart {
+		worklogs = tracker.StartedIn(worklogs, timespan.Timespan)
+	}
+	table, err := Worklogs(worklogs).asTable(show.Timespan, opts.sort)
+	if err != nil {
+		return nil, TableData{}, err
+	}
+	table.SyncedAt = syncedAt
+	table.Offline = offline
+	if opts.filterBy == filterByStart {
+		table.FilterBy = filterByStart
+	}
+	if cal := opts.calendar; cal != nil && len(table.Days) > 0 {
+		loc := show.Timespan.Start.Location()
+		table.DayKinds = dayKinds(cal, table.Days, loc)
+		if opts.norms && cal.Hours() > 0 {
+			table.Norms, table.Weeks = h.config.Norms.check(cal, table.Days, table.DaysSum, loc, time.Now())
+		}
+	}
+	if err := h.groupTable(&table, opts.groupBy, opts.sort); err != nil {
+		return nil, TableData{}, err
 	}
-	return Worklogs(worklogs).asTable(show.Timespan)
+	return worklogs, table, nil
 }
@@ -0,0 +1,88 @@
+package worklog
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const sortParam = "sort"
+
+// sortOrder — порядок задач в таблице, ?sort=total
+type sortOrder string
+
+const (
+	// по ключу: очередь по алфавиту, номера по возрастанию (TEST-2 раньше TEST-10)
+	sortByKey sortOrder = "key"
+	// по времени за период, сначала самые затратные
+	sortByTotal sortOrder = "total"
+	// по первой записи в периоде
+	sortByFirst sortOrder = "first"
+	// по названию задачи
+	sortBySummary sortOrder = "summary"
+)
+
+var sortOrders = []sortOrder{sortByKey, sortByTotal, sortByFirst, sortBySummary}
+
+func parseSortOrder(value string) (sortOrder, error) {
+	if value == "" {
+		return sortByKey, nil
+	}
+	if !slices.Contains(sortOrders, sortOrder(value)) {
+		return "", fmt.Errorf("unknown sort order: %q", value)
+	}
+	return sortOrder(value), nil
+}
+
+// sortRowspans упорядочивает задачи; при равенстве — по ключу,
+// чтобы один и тот же отчет всегда выглядел одинаково
+func sortRowspans(rowspans []Rowspan, order sortOrder) {
+	slices.SortFunc(rowspans, func(a, b Rowspan) int {
+		var c int
+		switch order {
+		case sortByTotal:
+			c = cmp.Compare(b.Sum, a.Sum)
+		case sortByFirst:
+			c = a.FirstActivity.Compare(b.FirstActivity)
+		case sortBySummary:
+			c = strings.Compare(strings.ToLower(a.Issue.Display), strings.ToLower(b.Issue.Display))
+		}
+		return cmp.Or(c, compareIssueKeys(a.Issue.Key, b.Issue.Key))
+	})
+}
+
+// sortGroups упорядочивает группы по названию или, при sortByTotal, по времени.
+// Группа задач без значения поля всегда последняя.
+func sortGroups(groups []Group, order sortOrder) {
+	slices.SortFunc(groups, func(a, b Group) int {
+		if (a.Key == "") != (b.Key == "") {
+			if a.Key == "" {
+				return 1
+			}
+			return -1
+		}
+		if order == sortByTotal {
+			if c := cmp.Compare(b.Sum, a.Sum); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.Title, b.Title)
+	})
+}
+
+// compareIssueKeys сравнивает ключи задач QUEUE-N: очередь как строку, номер как число
+func compareIssueKeys(a, b string) int {
+	queueA, numA, _ := strings.Cut(a, "-")
+	queueB, numB, _ := strings.Cut(b, "-")
+	if c := strings.Compare(queueA, queueB); c != 0 {
+		return c
+	}
+	na, errA := strconv.Atoi(numA)
+	nb, errB := strconv.Atoi(numB)
+	if errA != nil || errB != nil {
+		return strings.Compare(numA, numB)
+	}
+	return cmp.Compare(na, nb)
+}
@@ -1,52 +1,107 @@
+const path = (() => {
+    const re = RegExp(/\/worklog\/(?<createdBy>((team|issue|queue)\/)?[^\/]+)\/((from\/(?<from>[^\/]+)\/to\/(?<to>[^\/]+))|(?<preset>[^\/]+))(\/show\/((from\/(?<showFrom>[^\/]+)\/to\/(?<showTo>[^\/]+))|(?<showPreset>[^\/]+)))?/);
+    const params = re.exec(window.location.pathname).groups;
+
+    const getCreatedByPath = (createdBy) => `/worklog/${createdBy}`;
+    const getWorklogPath = (period) => `/${period.preset || "from/" + period.from + "/to/" + period.to}`;
+    const getShowPath = (period) => {
+        const params = period.preset ? period.preset : period.from ? "from/" + period.from + "/to/" + period.to : "";
+        return params ? `/show/${params}` : "";
+    };
+    const createdByPath = getCreatedByPath(params.createdBy);
+    const worklogPath = getWorklogPath(params);
+    const showPath = getShowPath({ preset: params.showPreset, from: params.showFrom, to: params.showTo });
+    return {
+        getCreatedByPath,
+        getWorklogPath,
+        getShowPath,
+        links: {
+            // параметры таблицы (группировка) сохраняются при смене периода
+            getWorklogLink: (period) => createdByPath + getWorklogPath(period) + showPath + window.location.search,
+            getShowLink: (period) => createdByPath + worklogPath + getShowPath(period) + window.location.search,
+        }
+    };
+})();
+
 (() => {
-    const path = (() => {
-        const re = RegExp(/\/worklog\/(?<createdBy>[^\/]+)\/((from\/(?<from>[^\/]+)\/to\/(?<to>[^\/]+))|(?<preset>[^\/]+))(\/show\/((from\/(?<showFrom>[^\/]+)\/to\/(?<showTo>[^\/]+))|(?<showPreset>[^\/]+)))?/);
-        const params = re.exec(window.location.pathname);
+    // ссылки на пресеты выводит шаблон, здесь к ним добавляется остальная часть пути
+    const setHeaderLinks = () => {
+        document.querySelectorAll(".header .worklog a[data-preset]").forEach((link) => {
+            link.href = path.links.getWorklogLink({ preset: link.dataset.preset });
+        });
+        document.querySelectorAll(".header .show a[data-preset]").forEach((link) => {
+            link.href = path.links.getShowLink({ preset: link.dataset.preset });
+        });
+    };
+    setHeaderLinks();
 
-        const getCreatedByPath = (createdBy) => `/worklog/${createdBy}`;
-        const getWorklogPath = (period) => `/${period.preset || period.from + "/" + period.to}`;
-        const getShowPath = (period) => {
-            const params = period.showPreset ? period.preset : period.from ? period.from + "/" + period.to : "";
-            return params ? `/show/${params}` : "";
-        };
-        const createdByPath = getCreatedByPath(params);
-        const worklogPath = getWorklogPath(params);
-        const showPath = getShowPath({ preset: params.showPreset, from: params.showFrom, to: params.showTo });
-        return {
-            getCreatedByPath,
-            getWorklogPath,
-            getShowPath,
-            links: {
-                getWorklogLink: (period) => createdByPath + getWorklogPath(period) + showPath,
-                getShowLink: (period) => createdByPath + worklogPath + getShowPath(period),
+    const setExportLinks = () => {
+        document.querySelectorAll(".export a").forEach((link) => {
+            const params = new URLSearchParams(window.location.search);
+            params.set("format", link.dataset.format);
+            if (link.dataset.sheet) {
+                params.set("sheet", link.dataset.sheet);
             }
+            link.href = `${window.location.pathname}?${params}`;
+        });
+    };
+    setExportLinks();
+
+    const setRefreshLink = () => {
+        const link = document.querySelector(".header .refresh");
+        if (link) {
+            const params = new URLSearchParams(window.location.search);
+            params.set("refresh", "1");
+            link.href = `${window.location.pathname}?${params}`;
+        }
+    };
+    setRefreshLink();
+
+    const setTableOptions = () => {
+        const params = new URLSearchParams(window.location.search);
+        const apply = () => {
+            params.delete("refresh");
+            window.location.search = params;
         };
-    })();
 
-    const appendHeaderLinks = () => {
-        const periods = [{ preset: "today", value: "Сегодня" }, { preset: "currentWeek", value: "Эта неделя" }, { preset: "currentMonth", value: "Этот месяц" }];
-        const worklog = (periods) => {
-            const el = document.querySelector(".header .worklog");
-            periods.forEach((period) => {
-                const link = document.createElement("a");
-                link.href = path.links.getWorklogLink(period);
-                link.textContent = period.value;
-                el.appendChild(link);
+        const groupBy = [...document.querySelectorAll(".table-options select.group-by")];
+        const fields = (params.get("groupBy") || "").split(",");
+        groupBy.forEach((select, i) => {
+            select.value = fields[i] || "";
+            select.addEventListener("change", () => {
+                const value = groupBy.map((s) => s.value).filter(Boolean);
+                if (value.length) {
+                    params.set("groupBy", value.join(","));
+                } else {
+                    params.delete("groupBy");
+                }
+                apply();
             });
-        };
-        const show = (periods) => {
-            const el = document.querySelector(".header .show");
-            periods.forEach((period) => {
-                const link = document.createElement("a");
-                link.href = path.links.getShowLink(period);
-                link.textContent = period.value;
-                el.appendChild(link);
+        });
+
+        const filterBy = document.querySelector(".header select.filter-by");
+        if (filterBy) {
+            filterBy.value = params.get("filterBy") || "createdAt";
+            filterBy.addEventListener("change", () => {
+                if (filterBy.value === "createdAt") {
+                    params.delete("filterBy");
+                } else {
+                    params.set("filterBy", filterBy.value);
+                }
+                apply();
             });
-        };
-        worklog(periods);
-        show(periods);
+        }
+
+        const sort = document.querySelector(".table-options select.sort");
+        if (sort) {
+            sort.value = params.get("sort") || "key";
+            sort.addEventListener("change", () => {
+                params.set("sort", sort.value);
+                apply();
+            });
+        }
     };
-    appendHeaderLinks();
+    setTableOptions();
 
     const setInputsValues = () => {
         const dateToISOString = (date) => {
@@ -83,4 +138,94 @@ function onWorklogSubmit() {
     } else {
         alert("Please fill in both start and end dates.");
     }
-}
\ No newline at end of file
+}
+(() => {
+    const table = document.querySelector("table.worklogs");
+    if (!table) {
+        return;
+    }
+
+    const issuePath = (issueKey) => `/worklog/issues/${encodeURIComponent(issueKey)}`;
+    const send = async (method, url, body) => {
+        const response = await fetch(url, {
+            method,
+            headers: { "Content-Type": "application/json" },
+            body: body ? JSON.stringify(body) : undefined,
+        });
+        if (!response.ok) {
+            const { error } = await response.json();
+            throw new Error(error.message);
+        }
+        window.location.reload();
+    };
+    const run = (promise) => promise.catch((error) => alert(error.message));
+
+    const createWorklog = (cell) => {
+        const { issue, day } = cell.dataset;
+        const duration = prompt(`${issue}, ${day}: duration (e.g. 1h 30m)`);
+        if (!duration) {
+            return;
+        }
+        const comment = prompt("Comment:", "") ?? "";
+        run(send("POST", issuePath(issue), { date: day, duration, comment, timezone: query.timezone }));
+    };
+    const updateWorklog = (cell) => {
+        const { issue, id, version } = cell.dataset;
+        const duration = prompt(`${issue}: duration`, cell.dataset.duration);
+        if (!duration) {
+            return;
+        }
+        const comment = prompt("Comment:", cell.dataset.comment);
+        if (comment === null) {
+            return;
+        }
+        run(send("PATCH", `${issuePath(issue)}/${id}`, { duration, comment, version: Number(version) }));
+    };
+    const deleteWorklog = (cell) => {
+        const { issue, id } = cell.dataset;
+        if (confirm(`Delete worklog ${cell.dataset.duration} from ${issue}?`)) {
+            run(send("DELETE", `${issuePath(issue)}/${id}`));
+        }
+    };
+
+    // свернутая группа скрывает свои задачи и вложенные группы
+    const toggleGroup = (header) => {
+        header.classList.toggle("collapsed");
+        const collapsed = [...table.querySelectorAll("tr.group.collapsed")].map((row) => row.dataset.path);
+        table.querySelectorAll("tr[data-path]").forEach((row) => {
+            const path = row.dataset.path;
+            row.hidden = collapsed.some((p) => path.startsWith(p + ".") || (path === p && !row.classList.contains("group")));
+        });
+    };
+
+    table.addEventListener("click", (event) => {
+        const header = event.target.closest("tr.group");
+        if (header) {
+            toggleGroup(header);
+            return;
+        }
+        const cell = event.target.closest("td.editable");
+        if (!cell) {
+            return;
+        }
+        if (event.target.closest("button.delete")) {
+            deleteWorklog(cell);
+        } else if (cell.dataset.id) {
+            updateWorklog(cell);
+        } else {
+            createWorklog(cell);
+        }
+    });
+})()
+
+document.querySelectorAll(".pending button.discard").forEach((button) => {
+    button.addEventListener("click", async () => {
+        const response = await fetch(`/worklog/pending/${button.dataset.operation}`, { method: "DELETE" });
+        if (response.ok) {
+            window.location.reload();
+        } else {
+            const { error } = await response.json();
+            alert(error.message);
+        }
+    });
+});
@@ -0,0 +1,171 @@
+package worklog
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/tracker/internal/calendar"
+)
+
+const (
+	teamParam       = "team"
+	teamPathPrefix  = pathPrefix + "/team/{" + teamParam + "}"
+	teamConcurrency = 4
+)
+
+// TeamUser — строка командного отчета: сколько участник списал по дням.
+// Если загрузить записи не удалось, заполнено только Error.
+type TeamUser struct {
+	Login string
+	Link  string
+	// часовой пояс, в котором считаются дни участника
+	Timezone string
+	DaysSum  []time.Duration
+	Sum      time.Duration
+	// нормы и статусы дней участника; Norm и Status — за прошедшие дни периода
+	Norms  []DayNorm
+	Norm   time.Duration
+	Status DayStatus
+	Error  string
+}
+type TeamTableData struct {
+	Days    []string
+	Users   []TeamUser
+	DaysSum []time.Duration
+	Sum     time.Duration
+	Failed  []string
+	// Трекер недоступен, часть записей взята из кэша
+	Offline  bool
+	FilterBy filterBy
+	DayKinds []calendar.Kind
+}
+type PageTeamContent struct {
+	Query Query[string]
+	Team  TeamTableData
+	Style template.CSS
+}
+type PageTeam struct {
+	Title   string
+	Content PageTeamContent
+}
+
+func (h *Handler) teamHandler(queryFn func(p PathParams) (*Query[time.Time], error)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		team := r.PathValue(teamParam)
+		members, ok := h.config.Teams[team]
+		if !ok {
+			http.Error(w, fmt.Sprintf("Unknown team: %s", team), http.StatusNotFound)
+			return
+		}
+
+		p := *activatedRoute(r)
+		p.CreatedBy = team
+		loc, err := h.location(r, "")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error parsing timezone: %v", err), http.StatusBadRequest)
+			return
+		}
+		p.Location = loc
+		q, err := queryFn(p)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error creating worklog query: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		// ссылки на отчеты участников повторяют период командного отчета
+		suffix := strings.TrimPrefix(withoutRefresh(r.URL), pathPrefix+"/team/"+team)
+		opts, err := parseTableOptions(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error parsing table options: %v", err), http.StatusBadRequest)
+			return
+		}
+		opts.calendar = h.teamCalendar(team)
+		table := h.getTeamTable(members, *q, opts, func(login string) (string, *time.Location) {
+			// ?tz= уже проверен выше
+			loc, _ := h.location(r, login)
+			return pathPrefix + "/" + login + suffix, loc
+		})
+		if opts.refresh && !table.Offline {
+			http.Redirect(w, r, withoutRefresh(r.URL), http.StatusSeeOther)
+			return
+		}
+
+		page := PageTeam{
+			Title: "Team " + team + ": " + q.Show.Title,
+			Content: PageTeamContent{
+				Query: formatQuery(*q),
+				Team:  table,
+				Style: h.templates.css,
+			},
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := h.templates.teamTpl.ExecuteTemplate(w, "index.html", page); err != nil {
+			http.Error(w, fmt.Sprintf("Template execution error: %v", err), 500)
+		}
+	}
+}
+
+// getTeamTable загружает записи участников параллельно, не больше teamConcurrency
+// запросов одновременно. Ошибка одного участника не мешает остальным.
+// Дни участника считаются в его часовом поясе: столбцы — те же календарные дни.
+func (h *Handler) getTeamTable(members []string, q Query[time.Time], opts tableOptions, member func(login string) (link string, loc *time.Location)) TeamTableData {
+	users := make([]TeamUser, len(members))
+	tables := make([]TableData, len(members))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, teamConcurrency)
+	for i, login := range members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			link, loc := member(login)
+			users[i] = TeamUser{Login: login, Link: link, Timezone: loc.String()}
+			q := queryIn(q, loc)
+			_, table, err := h.getWorklogsTable(h.authorWorklogs(login), q.CreatedAt, q.Show, tableOptions{refresh: opts.refresh, sort: sortByKey, filterBy: opts.filterBy, norms: true, calendar: opts.calendar})
+			if err != nil {
+				users[i].Error = err.Error()
+				return
+			}
+			tables[i] = table
+		}()
+	}
+	wg.Wait()
+
+	result := TeamTableData{Days: showDays(q.Show.Timespan.Start, q.Show.Timespan.End), FilterBy: opts.filterBy}
+	result.DaysSum = make([]time.Duration, len(result.Days))
+	if opts.calendar != nil {
+		result.DayKinds = dayKinds(opts.calendar, result.Days, q.Show.Timespan.Start.Location())
+	}
+	for i := range users {
+		if users[i].Error != "" {
+			result.Failed = append(result.Failed, users[i].Login)
+		} else {
+			users[i].DaysSum = make([]time.Duration, len(result.Days))
+			copy(users[i].DaysSum, tables[i].DaysSum)
+			users[i].Sum = tables[i].Sum
+			users[i].Norms = tables[i].Norms
+			if len(tables[i].Weeks) > 0 {
+				var logged time.Duration
+				for _, week := range tables[i].Weeks {
+					logged += week.Sum
+					users[i].Norm += week.Norm
+				}
+				users[i].Status = h.config.Norms.status(logged, users[i].Norm)
+			}
+			result.Offline = result.Offline || tables[i].Offline
+			for day, d := range users[i].DaysSum {
+				result.DaysSum[day] += d
+			}
+			result.Sum += users[i].Sum
+		}
+	}
+	result.Users = users
+	return result
+}
@@ -0,0 +1,45 @@
+package worklog
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const tzParam = "tz"
+
+// location выбирает часовой пояс отчета: ?tz=Europe/Berlin, пояс участника
+// login из настроек или общий пояс по умолчанию
+func (h *Handler) location(r *http.Request, login string) (*time.Location, error) {
+	if name := r.URL.Query().Get(tzParam); name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q: %w", name, err)
+		}
+		return loc, nil
+	}
+	return h.UserLocation(login), nil
+}
+
+// UserLocation — пояс участника из настроек, иначе общий пояс по умолчанию
+func (h *Handler) UserLocation(login string) *time.Location {
+	if loc, ok := h.config.UserLocations[login]; ok {
+		return loc
+	}
+	if h.config.Location != nil {
+		return h.config.Location
+	}
+	return time.Local
+}
+
+// queryIn переносит периоды отчета в пояс loc: те же календарные дни,
+// но полночь по местному времени loc
+func queryIn(q Query[time.Time], loc *time.Location) Query[time.Time] {
+	in := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	}
+	q.CreatedAt.Timespan.Start, q.CreatedAt.Timespan.End = in(q.CreatedAt.Timespan.Start), in(q.CreatedAt.Timespan.End)
+	q.Show.Timespan.Start, q.Show.Timespan.End = in(q.Show.Timespan.Start), in(q.Show.Timespan.End)
+	q.Timezone = loc.String()
+	return q
+}
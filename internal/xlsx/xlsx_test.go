@@ -0,0 +1,81 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"example.com/tracker/internal/xlsx"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := xlsx.ColumnName(tt.index); got != tt.want {
+			t.Errorf("ColumnName(%d) = %s, want %s", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	err := xlsx.Write(&buf, []xlsx.Sheet{
+		{Name: "Table", Rows: [][]any{{"issue", "sum"}, {"A-1 <&>", 1.5}, {nil, 2}}, OutlineLevels: []int{0, 1}},
+		{Name: "Worklogs"},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Write() produced invalid zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Write() missing %s", name)
+		}
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{`<c r="A2" t="inlineStr"><is><t xml:space="preserve">A-1 &lt;&amp;&gt;</t></is></c>`, `<c r="B2"><v>1.5</v></c>`, `<c r="B3"><v>2</v></c>`, `<outlinePr summaryBelow="0"/>`, `<row r="2" outlineLevel="1">`, `<row r="3">`} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet1.xml does not contain %s", want)
+		}
+	}
+	if strings.Contains(files["xl/worksheets/sheet2.xml"], "outlinePr") {
+		t.Errorf("sheet2.xml without outline levels contains outlinePr")
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="Worklogs" sheetId="2" r:id="rId2"/>`) {
+		t.Errorf("workbook.xml does not list the second sheet")
+	}
+}
+
+func TestWriteEmpty(t *testing.T) {
+	if err := xlsx.Write(io.Discard, nil); err == nil {
+		t.Error("Write() without sheets should return an error")
+	}
+}
@@ -2,59 +2,212 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"html/template"
 	"io/fs"
 	"log"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
+	"example.com/tracker/internal/calendar"
 	"example.com/tracker/internal/client"
 	"example.com/tracker/internal/config"
+	"example.com/tracker/internal/iam"
+	"example.com/tracker/internal/reminder"
 	"example.com/tracker/internal/server"
+	"example.com/tracker/internal/store"
 	"example.com/tracker/internal/tracker"
+	"example.com/tracker/internal/tracker/trackertest"
 	"example.com/tracker/internal/worklog"
 	"example.com/tracker/web"
 )
 
-func init() {
-	if loc, err := time.LoadLocation("Europe/Moscow"); err != nil {
-		fmt.Println(err)
+func main() {
+	fake := flag.Bool("fake", false, "use an in-process fake Tracker with demo data instead of the real API")
+	fakeUser := flag.String("fake-user", "demo", "login of the demo worklogs author in --fake mode")
+	flag.Parse()
+
+	// Load configuration
+	var cfg *config.Config
+	var err error
+	if *fake {
+		fakeServer := trackertest.NewServer()
+		defer fakeServer.Close()
+		fakeServer.User = *fakeUser
+		fakeServer.SeedDemo(*fakeUser, time.Now())
+		log.Printf("Using fake Tracker at %s, demo worklogs: /worklog/%s/currentMonth", fakeServer.URL, *fakeUser)
+		cfg, err = config.LoadFake(fakeServer.APIURL(), fakeServer.Token, fakeServer.OrgID)
 	} else {
-		time.Local = loc
+		cfg, err = config.Load()
+	}
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
 	}
+
+	trackerClient, err := newTrackerClient(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create tracker client: %v", err)
+	}
+
+	if args := flag.Args(); len(args) > 0 && args[0] == "report" {
+		if err := runReport(trackerClient, cfg, args[1:]); err != nil {
+			log.Fatalf("Failed to print report: %v", err)
+		}
+		return
+	}
+
+	serve(cfg, trackerClient)
 }
 
-func main() {
-	// Load configuration
-	cfg, err := config.Load()
+func newTrackerClient(cfg *config.Config) (*tracker.TrackerClient, error) {
+	authInterceptor, err := newAuthInterceptor(cfg)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		return nil, err
 	}
 
 	// Create HTTP client with interceptors
 	httpClient := client.New([]client.Interceptor{
-		tracker.AuthTokenInterceptor(cfg.YandexIAMToken, cfg.YandexOrgID),
+		authInterceptor,
+		client.RetryInterceptor(client.RetryConfig{}),
 		// client.LoggingInterceptor(), // uncomment for debugging
 	})
 
 	// Create tracker client
-	trackerClient := tracker.NewTrackerClient(tracker.Config{
-		HostURL: cfg.TrackerHost,
-		Client:  httpClient,
-		Ctx:     context.Background(),
-		Timeout: 10 * time.Second,
+	return tracker.NewTrackerClient(tracker.Config{
+		HostURL:    cfg.TrackerHost,
+		APIURL:     cfg.TrackerAPIURL,
+		APIVersion: cfg.TrackerAPIVersion,
+		Client:     httpClient,
+		Ctx:        context.Background(),
+		Timeout:    10 * time.Second,
+	}), nil
+}
+
+func newAuthInterceptor(cfg *config.Config) (client.Interceptor, error) {
+	authConfig := tracker.AuthConfig{OrgID: cfg.YandexOrgID}
+	if cfg.OrgType == config.OrgType360 {
+		authConfig.OrgHeader = tracker.OrgHeader360
+	}
+
+	var tokenSource tracker.TokenSource
+	switch {
+	case cfg.AuthScheme == config.AuthSchemeOAuth:
+		authConfig.Scheme = tracker.AuthSchemeOAuth
+		tokenSource = tracker.StaticToken(cfg.YandexOAuthToken)
+	case cfg.YandexSAKeyFile != "":
+		key, err := iam.LoadKey(cfg.YandexSAKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading service account key: %w", err)
+		}
+		if tokenSource, err = iam.NewTokenSource(iam.Config{Key: key}); err != nil {
+			return nil, fmt.Errorf("error creating iam token source: %w", err)
+		}
+	default:
+		tokenSource = tracker.StaticToken(cfg.YandexIAMToken)
+	}
+	return tracker.AuthInterceptor(tokenSource, authConfig), nil
+}
+
+// runReport печатает таблицу затрат времени в консоль:
+// tracker [--fake] report --user=<login> (--preset=today|lastWeek|last7days|... | --from=2006-01-02 --to=2006-01-02) [--by-start] [--tz=Europe/Berlin]
+func runReport(trackerClient *tracker.TrackerClient, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	user := flags.String("user", "", "worklog author login")
+	preset := flags.String("preset", "", "period preset: "+strings.Join(worklog.PresetNames(), ", "))
+	from := flags.String("from", "", "period start date, 2006-01-02")
+	to := flags.String("to", "", "period end date (exclusive), 2006-01-02")
+	byStart := flags.Bool("by-start", false, "select worklogs by work start date instead of creation date; "+tracker.StartWindowNote())
+	tz := flags.String("tz", "", "timezone of the report days, defaults to the user's or TIMEZONE")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	loc, ok := cfg.UserTimezones[*user]
+	if !ok {
+		loc = cfg.Timezone
+	}
+	if *tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			return fmt.Errorf("error loading timezone: %w", err)
+		}
+	}
+	if *preset == "" && *from == "" && *to == "" {
+		*preset = "today"
+	}
+
+	return worklog.WriteReport(os.Stdout, trackerClient, worklog.ReportParams{
+		CreatedBy: *user,
+		Preset:    *preset,
+		From:      *from,
+		To:        *to,
+		ByStart:   *byStart,
+		Location:  loc,
 	})
+}
 
+func serve(cfg *config.Config, trackerClient *tracker.TrackerClient) {
 	// Load templates
 	indexTpl := template.Must(template.ParseFS(web.Templates, "templates/index.html"))
 
+	// Open local worklog cache
+	var worklogStore *store.Store
+	if cfg.CacheFile != "" {
+		var err error
+		if worklogStore, err = store.Open(cfg.CacheFile); err != nil {
+			log.Fatalf("Failed to open worklog cache: %v", err)
+		}
+	}
+
+	// Load production calendars
+	cal, teamCalendars, err := loadCalendars(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load calendar: %v", err)
+	}
+
 	// Create worklog handler
-	worklogHandler, err := worklog.NewHandler(trackerClient, indexTpl)
+	worklogHandler, err := worklog.NewHandler(trackerClient, indexTpl, worklog.Config{
+		Teams:         cfg.Teams,
+		Store:         worklogStore,
+		CacheMaxAge:   cfg.CacheMaxAge,
+		Location:      cfg.Timezone,
+		UserLocations: cfg.UserTimezones,
+		Calendar:      cal,
+		TeamCalendars: teamCalendars,
+		Norms: worklog.Norms{
+			Weekly:    cfg.NormWeekly,
+			Tolerance: cfg.NormTolerance,
+		},
+	})
 	if err != nil {
 		log.Fatalf("Failed to create worklog handler: %v", err)
 	}
 
+	// Send worklog changes made while Tracker was unavailable
+	if worklogStore != nil {
+		go worklogHandler.RunReplay(context.Background(), time.Minute)
+	}
+
+	// Remind users who have not logged enough time
+	if cfg.ReminderAt != "" {
+		scheduler, err := reminder.New(reminder.Config{
+			Users:      cfg.ReminderUsers,
+			At:         cfg.ReminderAt,
+			Location:   worklogHandler.UserLocation,
+			Calendar:   worklogHandler.UserCalendar,
+			Tolerance:  cfg.NormTolerance,
+			GetWorklog: trackerClient.GetWorklog,
+			Notifiers:  newNotifiers(cfg),
+			BaseURL:    cfg.PublicURL,
+		})
+		if err != nil {
+			log.Fatalf("Failed to create reminder: %v", err)
+		}
+		go scheduler.Run(context.Background())
+	}
+
 	// Setup routes
 	mux := http.NewServeMux()
 
@@ -84,6 +237,67 @@ func main() {
 	server.StartServer(handler, cfg.ServerAddr)
 }
 
+// newNotifiers создает уведомители, для которых заданы настройки
+func newNotifiers(cfg *config.Config) []reminder.Notifier {
+	var notifiers []reminder.Notifier
+	if cfg.SMTPAddr != "" {
+		notifiers = append(notifiers, &reminder.SMTPNotifier{
+			Addr:     cfg.SMTPAddr,
+			From:     cfg.SMTPFrom,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			Emails:   cfg.UserEmails,
+			Domain:   cfg.EmailDomain,
+		})
+	}
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, &reminder.WebhookNotifier{URL: cfg.WebhookURL})
+	}
+	if cfg.TelegramBotToken != "" {
+		notifiers = append(notifiers, &reminder.TelegramNotifier{
+			Token:   cfg.TelegramBotToken,
+			APIURL:  cfg.TelegramAPIURL,
+			ChatIDs: cfg.TelegramChats,
+		})
+	}
+	return notifiers
+}
+
+// loadCalendars читает общий производственный календарь и собирает календари
+// команд: к общему по порядку добавляются дни переопределений из TEAM_CALENDARS
+func loadCalendars(cfg *config.Config) (*calendar.Calendar, map[string]*calendar.Calendar, error) {
+	var days []calendar.Day
+	if cfg.CalendarFile != "" {
+		var err error
+		if days, err = calendar.Load(cfg.CalendarFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	base := calendar.New(cfg.NormDaily, days...)
+
+	overrides := map[string][]calendar.Day{}
+	for name, path := range cfg.CalendarOverrides {
+		days, err := calendar.Load(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("override %s: %w", name, err)
+		}
+		overrides[name] = days
+	}
+	teams := map[string]*calendar.Calendar{}
+	for team, names := range cfg.TeamCalendars {
+		cal := base
+		for _, name := range names {
+			days, ok := overrides[name]
+			if !ok {
+				return nil, nil, fmt.Errorf("team %s: unknown calendar override %q", team, name)
+			}
+			cal = cal.With(days...)
+		}
+		teams[team] = cal
+	}
+	return base, teams, nil
+}
+
 func handleStatic(mux *http.ServeMux) {
 	assetsSubFS, err := fs.Sub(web.StaticFiles, "static")
 	if err != nil {